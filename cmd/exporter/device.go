@@ -0,0 +1,86 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/deviceinfo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	metrics = append(metrics, &Metric{
+		Source:   fritzboxmetrics.SourceTR64,
+		Service:  "urn:dslforum-org:service:UserInterface:1",
+		Action:   "GetInfo",
+		Output:   "NewUpgradeAvailable",
+		Optional: true,
+		Desc: prometheus.NewDesc(
+			"fritzbox_firmware_update_available",
+			"A firmware update is available (1) or not (0)",
+			[]string{"gateway"},
+			nil,
+		),
+		MetricType: prometheus.GaugeValue,
+	})
+}
+
+var (
+	deviceInfo = prometheus.NewDesc(
+		"fritzbox_info",
+		"Information about the FRITZ!Box, always 1",
+		[]string{"gateway", "model", "serial", "firmware_version", "hardware_version", "udn", "friendly_name"},
+		nil,
+	)
+	deviceUptime = prometheus.NewDesc(
+		"fritzbox_device_uptime_seconds",
+		"Time since the FRITZ!Box was started",
+		[]string{"gateway"},
+		nil,
+	)
+
+	deviceDescs = []*prometheus.Desc{deviceInfo, deviceUptime}
+)
+
+// collectDeviceInfo exports the model and firmware of the FRITZ!Box. If
+// DeviceInfo can't be called, the info is built from the description only.
+func (fc *FritzboxCollector) collectDeviceInfo(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	client, err := deviceinfo.New(root, fritzboxmetrics.SourceTR64)
+	if err != nil {
+		// Not a TR-064 device
+		return
+	}
+
+	device := client.Service.Device
+	model := device.ModelName
+	var serial, firmware, hardware string
+	if bundle := root.Bundle(); bundle != nil {
+		// Advertised in tr64desc.xml
+		firmware = bundle.FirmwareVersion
+	}
+
+	info, err := client.GetInfo(ctx)
+	if err != nil {
+		reportCallError(deviceinfo.ServiceType, "GetInfo", err)
+	} else {
+		model, serial, firmware, hardware = info.ModelName, info.SerialNumber, info.SoftwareVersion, info.HardwareVersion
+		ch <- prometheus.MustNewConstMetric(deviceUptime, prometheus.GaugeValue, float64(info.UpTime), fc.Gateway)
+	}
+
+	ch <- prometheus.MustNewConstMetric(deviceInfo, prometheus.GaugeValue, 1,
+		fc.Gateway, model, serial, firmware, hardware, device.UDN, device.FriendlyName)
+}
@@ -0,0 +1,69 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/deviceinfo"
+)
+
+var deviceMetricNames = []string{"fritzbox_device_uptime_seconds", "fritzbox_firmware_update_available", "fritzbox_info"}
+
+func TestCollectDeviceInfo(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse(deviceinfo.ServiceType, "GetInfo", map[string]string{
+		"NewManufacturerName": "AVM",
+		"NewManufacturerOUI":  "00040E",
+		"NewModelName":        "FRITZ!Box 7590",
+		"NewDescription":      "FRITZ!Box 7590 154.07.29",
+		"NewProductClass":     "AVMFB",
+		"NewSerialNumber":     "3431C4000001",
+		"NewSoftwareVersion":  "154.07.29",
+		"NewHardwareVersion":  "FRITZ!Box 7590",
+		"NewSpecVersion":      "1.0",
+		"NewProvisioningCode": "",
+		"NewUpTime":           "86400",
+		"NewDeviceLog":        "",
+	})
+	s.SetResponse("urn:dslforum-org:service:UserInterface:1", "GetInfo", map[string]string{
+		"NewUpgradeAvailable": "1",
+		"NewX_AVM-DE_Version": "154.07.50",
+	})
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_device_uptime_seconds Time since the FRITZ!Box was started
+# TYPE fritzbox_device_uptime_seconds gauge
+fritzbox_device_uptime_seconds{gateway="fritz.box"} 86400
+# HELP fritzbox_firmware_update_available A firmware update is available (1) or not (0)
+# TYPE fritzbox_firmware_update_available gauge
+fritzbox_firmware_update_available{gateway="fritz.box"} 1
+# HELP fritzbox_info Information about the FRITZ!Box, always 1
+# TYPE fritzbox_info gauge
+fritzbox_info{firmware_version="154.07.29",friendly_name="FRITZ!Box 7590",gateway="fritz.box",hardware_version="FRITZ!Box 7590",model="FRITZ!Box 7590",serial="3431C4000001",udn="uuid:739f2409-bccb-40e7-8e6c-3431C4000001"} 1
+`, deviceMetricNames...)
+}
+
+func TestCollectDeviceInfoFromDescription(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.InjectFault(deviceinfo.ServiceType, "GetInfo", fritzboxtest.Fault{UPnPErrorCode: 606, UPnPErrorDescription: "Action not authorized"})
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_info Information about the FRITZ!Box, always 1
+# TYPE fritzbox_info gauge
+fritzbox_info{firmware_version="154.07.29",friendly_name="FRITZ!Box 7590",gateway="fritz.box",hardware_version="",model="FRITZ!Box 7590",serial="",udn="uuid:739f2409-bccb-40e7-8e6c-3431C4000001"} 1
+`, deviceMetricNames...)
+}
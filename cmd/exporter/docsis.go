@@ -0,0 +1,120 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"log"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var docsisLabels = []string{"gateway", "direction", "type", "channel_id"}
+
+var (
+	docsisPowerLevel = prometheus.NewDesc(
+		"fritzbox_docsis_power_level_dbmv",
+		"Power level of the DOCSIS channel",
+		docsisLabels,
+		nil,
+	)
+	docsisFrequency = prometheus.NewDesc(
+		"fritzbox_docsis_frequency_hertz",
+		"Frequency of the DOCSIS channel, the lower edge for DOCSIS 3.1 channels given as range",
+		docsisLabels,
+		nil,
+	)
+	docsisMSE = prometheus.NewDesc(
+		"fritzbox_docsis_mse_db",
+		"Mean squared error of the DOCSIS 3.0 downstream channel",
+		docsisLabels,
+		nil,
+	)
+	docsisMER = prometheus.NewDesc(
+		"fritzbox_docsis_mer_db",
+		"Modulation error ratio of the DOCSIS 3.1 downstream channel",
+		docsisLabels,
+		nil,
+	)
+	docsisModulation = prometheus.NewDesc(
+		"fritzbox_docsis_modulation_info",
+		"Modulation of the DOCSIS channel, always 1",
+		append(docsisLabels, "modulation"),
+		nil,
+	)
+	docsisCorrectedErrors = prometheus.NewDesc(
+		"fritzbox_docsis_corrected_errors_total",
+		"Corrected errors of the DOCSIS downstream channel",
+		docsisLabels,
+		nil,
+	)
+	docsisUncorrectableErrors = prometheus.NewDesc(
+		"fritzbox_docsis_uncorrectable_errors_total",
+		"Uncorrectable errors of the DOCSIS downstream channel",
+		docsisLabels,
+		nil,
+	)
+
+	docsisDescs = []*prometheus.Desc{
+		docsisPowerLevel,
+		docsisFrequency,
+		docsisMSE,
+		docsisMER,
+		docsisModulation,
+		docsisCorrectedErrors,
+		docsisUncorrectableErrors,
+	}
+)
+
+// collectDOCSIS exports the channel tables of cable models from the web interface
+func (fc *FritzboxCollector) collectDOCSIS(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !fc.DOCSIS {
+		return
+	}
+
+	info, err := fc.Session.DOCSISInfo(ctx)
+	if err != nil {
+		log.Printf("could not collect DOCSIS channels: %v", err)
+		collectErrors.Inc()
+		return
+	}
+
+	fc.collectDOCSISChannels(ch, "downstream", "docsis30", info.Downstream.DOCSIS30)
+	fc.collectDOCSISChannels(ch, "downstream", "docsis31", info.Downstream.DOCSIS31)
+	fc.collectDOCSISChannels(ch, "upstream", "docsis30", info.Upstream.DOCSIS30)
+	fc.collectDOCSISChannels(ch, "upstream", "docsis31", info.Upstream.DOCSIS31)
+}
+
+func (fc *FritzboxCollector) collectDOCSISChannels(ch chan<- prometheus.Metric, direction, docsisType string, channels []fritzboxmetrics.DOCSISChannel) {
+	for _, c := range channels {
+		labels := []string{fc.Gateway, direction, docsisType, string(c.ChannelID)}
+		value := func(desc *prometheus.Desc, valueType prometheus.ValueType, v fritzboxmetrics.DOCSISValue, scale float64) {
+			if f, ok := v.Float64(); ok {
+				ch <- prometheus.MustNewConstMetric(desc, valueType, f*scale, labels...)
+			}
+		}
+
+		value(docsisPowerLevel, prometheus.GaugeValue, c.PowerLevel, 1)
+		value(docsisFrequency, prometheus.GaugeValue, c.Frequency, 1e6)
+		value(docsisMSE, prometheus.GaugeValue, c.MSE, 1)
+		value(docsisMER, prometheus.GaugeValue, c.MER, 1)
+		value(docsisCorrectedErrors, prometheus.CounterValue, c.CorrectedErrors, 1)
+		value(docsisUncorrectableErrors, prometheus.CounterValue, c.UncorrectableErrors, 1)
+		if modulation := c.ModulationName(); modulation != "" {
+			ch <- prometheus.MustNewConstMetric(docsisModulation, prometheus.GaugeValue, 1, append(labels, modulation)...)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+func TestCollectDOCSIS(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetWebPage("docInfo", fritzboxtest.DOCSISInfo)
+	fc.DOCSIS = true
+	fc.Session = fc.Client.NewSession(s.URL)
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_docsis_corrected_errors_total Corrected errors of the DOCSIS downstream channel
+# TYPE fritzbox_docsis_corrected_errors_total counter
+fritzbox_docsis_corrected_errors_total{channel_id="33",direction="downstream",gateway="fritz.box",type="docsis31"} 5083
+fritzbox_docsis_corrected_errors_total{channel_id="7",direction="downstream",gateway="fritz.box",type="docsis30"} 12
+fritzbox_docsis_corrected_errors_total{channel_id="8",direction="downstream",gateway="fritz.box",type="docsis30"} 301
+# HELP fritzbox_docsis_frequency_hertz Frequency of the DOCSIS channel, the lower edge for DOCSIS 3.1 channels given as range
+# TYPE fritzbox_docsis_frequency_hertz gauge
+fritzbox_docsis_frequency_hertz{channel_id="1",direction="upstream",gateway="fritz.box",type="docsis30"} 4.46e+07
+fritzbox_docsis_frequency_hertz{channel_id="2",direction="upstream",gateway="fritz.box",type="docsis30"} 5.1e+07
+fritzbox_docsis_frequency_hertz{channel_id="33",direction="downstream",gateway="fritz.box",type="docsis31"} 7.518e+08
+fritzbox_docsis_frequency_hertz{channel_id="7",direction="downstream",gateway="fritz.box",type="docsis30"} 5.38e+08
+fritzbox_docsis_frequency_hertz{channel_id="8",direction="downstream",gateway="fritz.box",type="docsis30"} 5.46e+08
+fritzbox_docsis_frequency_hertz{channel_id="9",direction="upstream",gateway="fritz.box",type="docsis31"} 2.98e+07
+# HELP fritzbox_docsis_mer_db Modulation error ratio of the DOCSIS 3.1 downstream channel
+# TYPE fritzbox_docsis_mer_db gauge
+fritzbox_docsis_mer_db{channel_id="33",direction="downstream",gateway="fritz.box",type="docsis31"} 42
+# HELP fritzbox_docsis_modulation_info Modulation of the DOCSIS channel, always 1
+# TYPE fritzbox_docsis_modulation_info gauge
+fritzbox_docsis_modulation_info{channel_id="1",direction="upstream",gateway="fritz.box",modulation="64QAM",type="docsis30"} 1
+fritzbox_docsis_modulation_info{channel_id="2",direction="upstream",gateway="fritz.box",modulation="64QAM",type="docsis30"} 1
+fritzbox_docsis_modulation_info{channel_id="33",direction="downstream",gateway="fritz.box",modulation="4096QAM",type="docsis31"} 1
+fritzbox_docsis_modulation_info{channel_id="7",direction="downstream",gateway="fritz.box",modulation="256QAM",type="docsis30"} 1
+fritzbox_docsis_modulation_info{channel_id="8",direction="downstream",gateway="fritz.box",modulation="256QAM",type="docsis30"} 1
+fritzbox_docsis_modulation_info{channel_id="9",direction="upstream",gateway="fritz.box",modulation="1024QAM",type="docsis31"} 1
+# HELP fritzbox_docsis_mse_db Mean squared error of the DOCSIS 3.0 downstream channel
+# TYPE fritzbox_docsis_mse_db gauge
+fritzbox_docsis_mse_db{channel_id="7",direction="downstream",gateway="fritz.box",type="docsis30"} -37.6
+fritzbox_docsis_mse_db{channel_id="8",direction="downstream",gateway="fritz.box",type="docsis30"} -36.4
+# HELP fritzbox_docsis_power_level_dbmv Power level of the DOCSIS channel
+# TYPE fritzbox_docsis_power_level_dbmv gauge
+fritzbox_docsis_power_level_dbmv{channel_id="1",direction="upstream",gateway="fritz.box",type="docsis30"} 44.3
+fritzbox_docsis_power_level_dbmv{channel_id="2",direction="upstream",gateway="fritz.box",type="docsis30"} 43
+fritzbox_docsis_power_level_dbmv{channel_id="33",direction="downstream",gateway="fritz.box",type="docsis31"} 7.2
+fritzbox_docsis_power_level_dbmv{channel_id="7",direction="downstream",gateway="fritz.box",type="docsis30"} 5.1
+fritzbox_docsis_power_level_dbmv{channel_id="8",direction="downstream",gateway="fritz.box",type="docsis30"} 4.4
+fritzbox_docsis_power_level_dbmv{channel_id="9",direction="upstream",gateway="fritz.box",type="docsis31"} 38.5
+# HELP fritzbox_docsis_uncorrectable_errors_total Uncorrectable errors of the DOCSIS downstream channel
+# TYPE fritzbox_docsis_uncorrectable_errors_total counter
+fritzbox_docsis_uncorrectable_errors_total{channel_id="33",direction="downstream",gateway="fritz.box",type="docsis31"} 2
+fritzbox_docsis_uncorrectable_errors_total{channel_id="7",direction="downstream",gateway="fritz.box",type="docsis30"} 0
+fritzbox_docsis_uncorrectable_errors_total{channel_id="8",direction="downstream",gateway="fritz.box",type="docsis30"} 17
+`, "fritzbox_docsis_corrected_errors_total", "fritzbox_docsis_frequency_hertz", "fritzbox_docsis_mer_db",
+		"fritzbox_docsis_modulation_info", "fritzbox_docsis_mse_db", "fritzbox_docsis_power_level_dbmv",
+		"fritzbox_docsis_uncorrectable_errors_total")
+}
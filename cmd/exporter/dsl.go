@@ -0,0 +1,106 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const wanDSLInterfaceConfigService = "urn:dslforum-org:service:WANDSLInterfaceConfig:1"
+
+func init() {
+	metrics = append(metrics, dslMetrics()...)
+}
+
+// dslMetrics are the line quality metrics of WANDSLInterfaceConfig.
+// They are optional, as cable and fiber models don't offer the service.
+func dslMetrics() []*Metric {
+	var m []*Metric
+
+	// GetInfo
+	m = append(m, dslDirections("GetInfo", "fritzbox_dsl_datarate_bits_per_second",
+		"Current DSL data rate", "NewUpstreamCurrRate", "NewDownstreamCurrRate", 1000, prometheus.GaugeValue)...)
+	m = append(m, dslDirections("GetInfo", "fritzbox_dsl_max_datarate_bits_per_second",
+		"Maximum attainable DSL data rate", "NewUpstreamMaxRate", "NewDownstreamMaxRate", 1000, prometheus.GaugeValue)...)
+	m = append(m, dslDirections("GetInfo", "fritzbox_dsl_noise_margin_db",
+		"DSL signal to noise margin", "NewUpstreamNoiseMargin", "NewDownstreamNoiseMargin", 0.1, prometheus.GaugeValue)...)
+	m = append(m, dslDirections("GetInfo", "fritzbox_dsl_attenuation_db",
+		"DSL line attenuation", "NewUpstreamAttenuation", "NewDownstreamAttenuation", 0.1, prometheus.GaugeValue)...)
+	m = append(m, dslDirections("GetInfo", "fritzbox_dsl_power_dbm",
+		"DSL output power", "NewUpstreamPower", "NewDownstreamPower", 0.1, prometheus.GaugeValue)...)
+	m = append(m, &Metric{
+		Source:   fritzboxmetrics.SourceTR64,
+		Service:  wanDSLInterfaceConfigService,
+		Action:   "GetInfo",
+		Output:   "NewStatus",
+		States:   true,
+		Optional: true,
+		Desc: prometheus.NewDesc(
+			"fritzbox_dsl_status",
+			"Status of the DSL line, 1 for the current state",
+			[]string{"gateway", "state"},
+			nil,
+		),
+		MetricType: prometheus.GaugeValue,
+	})
+
+	// GetStatisticsTotal, the errors counted by the central office (ATU-C) occurred upstream
+	m = append(m, dslDirections("GetStatisticsTotal", "fritzbox_dsl_crc_errors_total",
+		"DSL cyclic redundancy check errors", "NewATUCCRCErrors", "NewCRCErrors", 0, prometheus.CounterValue)...)
+	m = append(m, dslDirections("GetStatisticsTotal", "fritzbox_dsl_fec_errors_total",
+		"DSL forward error corrections", "NewATUCFECErrors", "NewFECErrors", 0, prometheus.CounterValue)...)
+	m = append(m, dslDirections("GetStatisticsTotal", "fritzbox_dsl_hec_errors_total",
+		"DSL header error check errors", "NewATUCHECErrors", "NewHECErrors", 0, prometheus.CounterValue)...)
+	m = append(m, dslMetric("GetStatisticsTotal", "NewErroredSecs", prometheus.NewDesc(
+		"fritzbox_dsl_errored_seconds_total",
+		"Seconds with DSL errors",
+		[]string{"gateway"},
+		nil,
+	), prometheus.CounterValue))
+	m = append(m, dslMetric("GetStatisticsTotal", "NewSeverelyErroredSecs", prometheus.NewDesc(
+		"fritzbox_dsl_severely_errored_seconds_total",
+		"Seconds with severe DSL errors",
+		[]string{"gateway"},
+		nil,
+	), prometheus.CounterValue))
+
+	return m
+}
+
+// dslDirections returns a metric with an upstream and a downstream series
+func dslDirections(action, name, help, upstream, downstream string, scale float64, metricType prometheus.ValueType) []*Metric {
+	desc := func(direction string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{"gateway"}, prometheus.Labels{"direction": direction})
+	}
+
+	up := dslMetric(action, upstream, desc("upstream"), metricType)
+	up.Scale = scale
+	down := dslMetric(action, downstream, desc("downstream"), metricType)
+	down.Scale = scale
+	return []*Metric{up, down}
+}
+
+func dslMetric(action, output string, desc *prometheus.Desc, metricType prometheus.ValueType) *Metric {
+	return &Metric{
+		Source:     fritzboxmetrics.SourceTR64,
+		Service:    wanDSLInterfaceConfigService,
+		Action:     action,
+		Output:     output,
+		Optional:   true,
+		Desc:       desc,
+		MetricType: metricType,
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "testing"
+
+func TestCollectDSL(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse(wanDSLInterfaceConfigService, "GetInfo", map[string]string{
+		"NewEnable":                "1",
+		"NewStatus":                "Up",
+		"NewUpstreamCurrRate":      "40000",
+		"NewDownstreamCurrRate":    "116789",
+		"NewUpstreamMaxRate":       "46512",
+		"NewDownstreamMaxRate":     "133224",
+		"NewUpstreamNoiseMargin":   "90",
+		"NewDownstreamNoiseMargin": "110",
+		"NewUpstreamAttenuation":   "80",
+		"NewDownstreamAttenuation": "130",
+		"NewUpstreamPower":         "145",
+		"NewDownstreamPower":       "512",
+	})
+	s.SetResponse(wanDSLInterfaceConfigService, "GetStatisticsTotal", map[string]string{
+		"NewCRCErrors":           "21",
+		"NewATUCCRCErrors":       "3",
+		"NewFECErrors":           "1024",
+		"NewATUCFECErrors":       "0",
+		"NewHECErrors":           "0",
+		"NewATUCHECErrors":       "0",
+		"NewErroredSecs":         "17",
+		"NewSeverelyErroredSecs": "2",
+	})
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_dsl_attenuation_db DSL line attenuation
+# TYPE fritzbox_dsl_attenuation_db gauge
+fritzbox_dsl_attenuation_db{direction="downstream",gateway="fritz.box"} 13
+fritzbox_dsl_attenuation_db{direction="upstream",gateway="fritz.box"} 8
+# HELP fritzbox_dsl_crc_errors_total DSL cyclic redundancy check errors
+# TYPE fritzbox_dsl_crc_errors_total counter
+fritzbox_dsl_crc_errors_total{direction="downstream",gateway="fritz.box"} 21
+fritzbox_dsl_crc_errors_total{direction="upstream",gateway="fritz.box"} 3
+# HELP fritzbox_dsl_datarate_bits_per_second Current DSL data rate
+# TYPE fritzbox_dsl_datarate_bits_per_second gauge
+fritzbox_dsl_datarate_bits_per_second{direction="downstream",gateway="fritz.box"} 1.16789e+08
+fritzbox_dsl_datarate_bits_per_second{direction="upstream",gateway="fritz.box"} 4e+07
+# HELP fritzbox_dsl_errored_seconds_total Seconds with DSL errors
+# TYPE fritzbox_dsl_errored_seconds_total counter
+fritzbox_dsl_errored_seconds_total{gateway="fritz.box"} 17
+# HELP fritzbox_dsl_fec_errors_total DSL forward error corrections
+# TYPE fritzbox_dsl_fec_errors_total counter
+fritzbox_dsl_fec_errors_total{direction="downstream",gateway="fritz.box"} 1024
+fritzbox_dsl_fec_errors_total{direction="upstream",gateway="fritz.box"} 0
+# HELP fritzbox_dsl_max_datarate_bits_per_second Maximum attainable DSL data rate
+# TYPE fritzbox_dsl_max_datarate_bits_per_second gauge
+fritzbox_dsl_max_datarate_bits_per_second{direction="downstream",gateway="fritz.box"} 1.33224e+08
+fritzbox_dsl_max_datarate_bits_per_second{direction="upstream",gateway="fritz.box"} 4.6512e+07
+# HELP fritzbox_dsl_noise_margin_db DSL signal to noise margin
+# TYPE fritzbox_dsl_noise_margin_db gauge
+fritzbox_dsl_noise_margin_db{direction="downstream",gateway="fritz.box"} 11
+fritzbox_dsl_noise_margin_db{direction="upstream",gateway="fritz.box"} 9
+# HELP fritzbox_dsl_power_dbm DSL output power
+# TYPE fritzbox_dsl_power_dbm gauge
+fritzbox_dsl_power_dbm{direction="downstream",gateway="fritz.box"} 51.2
+fritzbox_dsl_power_dbm{direction="upstream",gateway="fritz.box"} 14.5
+# HELP fritzbox_dsl_severely_errored_seconds_total Seconds with severe DSL errors
+# TYPE fritzbox_dsl_severely_errored_seconds_total counter
+fritzbox_dsl_severely_errored_seconds_total{gateway="fritz.box"} 2
+# HELP fritzbox_dsl_status Status of the DSL line, 1 for the current state
+# TYPE fritzbox_dsl_status gauge
+fritzbox_dsl_status{gateway="fritz.box",state="Disabled"} 0
+fritzbox_dsl_status{gateway="fritz.box",state="Error"} 0
+fritzbox_dsl_status{gateway="fritz.box",state="EstablishingLink"} 0
+fritzbox_dsl_status{gateway="fritz.box",state="Initializing"} 0
+fritzbox_dsl_status{gateway="fritz.box",state="NoSignal"} 0
+fritzbox_dsl_status{gateway="fritz.box",state="Up"} 1
+`, "fritzbox_dsl_attenuation_db", "fritzbox_dsl_crc_errors_total", "fritzbox_dsl_datarate_bits_per_second",
+		"fritzbox_dsl_errored_seconds_total", "fritzbox_dsl_fec_errors_total", "fritzbox_dsl_max_datarate_bits_per_second",
+		"fritzbox_dsl_noise_margin_db", "fritzbox_dsl_power_dbm", "fritzbox_dsl_severely_errored_seconds_total",
+		"fritzbox_dsl_status")
+}
@@ -0,0 +1,82 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"log"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const wanIPConnectionService = "urn:schemas-upnp-org:service:WANIPConnection:1"
+
+var (
+	wanConnectionStatusChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_wan_connection_status_changes_total",
+		Help: "Number of WAN connection status changes reported by events, by new status.",
+	}, []string{"gateway", "status"})
+	wanExternalIPChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_wan_external_ip_changes_total",
+		Help: "Number of external IP address changes reported by events.",
+	}, []string{"gateway"})
+)
+
+// EventWatcher counts changes of the WAN connection between scrapes
+// using UPnP event subscriptions.
+type EventWatcher struct {
+	Gateway string
+	Manager *fritzboxmetrics.SubscriptionManager
+
+	lastStatus string
+	lastIP     string
+}
+
+// Subscribe subscribes to the WAN connection events of the services tree
+func (w *EventWatcher) Subscribe(root *fritzboxmetrics.Root) {
+	service, ok := root.Services[wanIPConnectionService]
+	if !ok {
+		log.Printf("cannot subscribe to events: service %s not found", wanIPConnectionService)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	if err := w.Manager.Subscribe(ctx, service); err != nil {
+		log.Printf("cannot subscribe to events of %s: %v", wanIPConnectionService, err)
+	}
+}
+
+// Run counts the received events until the manager is closed
+func (w *EventWatcher) Run() {
+	for ev := range w.Manager.Events() {
+		if status, ok := ev.Variables["ConnectionStatus"]; ok {
+			// The initial event only reports the current state
+			if w.lastStatus != "" && status != w.lastStatus {
+				wanConnectionStatusChanges.WithLabelValues(w.Gateway, status).Inc()
+			}
+			w.lastStatus = status
+		}
+
+		if ip, ok := ev.Variables["ExternalIPAddress"]; ok {
+			if w.lastIP != "" && ip != w.lastIP {
+				wanExternalIPChanges.WithLabelValues(w.Gateway).Inc()
+			}
+			w.lastIP = ip
+		}
+	}
+}
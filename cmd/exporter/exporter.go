@@ -15,11 +15,17 @@ package main
 // limitations under the License.
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	envstruct "github.com/mxschmitt/golang-env-struct"
@@ -29,27 +35,72 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-const serviceLoadRetryTime = 1 * time.Minute
+const (
+	serviceLoadRetryTime  = 1 * time.Minute
+	serviceRevalidateTime = 1 * time.Hour
+	scrapeTimeout         = 30 * time.Second
+	discoverTimeout       = 3 * time.Second
+	shutdownTimeout       = 5 * time.Second
+)
 
 var (
 	collectErrors = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "fritzbox_exporter_collect_errors",
 		Help: "Number of collection errors.",
 	})
+	actionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "fritzbox_exporter_action_errors",
+		Help: "Number of failed action calls by reason (not_authorized, not_supported, other).",
+	}, []string{"reason"})
 )
 
+// registerClientStats exports the request counters of the client
+func registerClientStats(client *fritzboxmetrics.Client) {
+	prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "fritzbox_exporter_requests_total",
+		Help: "Number of HTTP requests sent to the FRITZ!Box.",
+	}, func() float64 {
+		return float64(client.Stats().Requests)
+	}))
+	prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "fritzbox_exporter_request_retries_total",
+		Help: "Number of HTTP requests repeated after an authentication challenge.",
+	}, func() float64 {
+		return float64(client.Stats().Retries)
+	}))
+	prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "fritzbox_exporter_auth_challenges_total",
+		Help: "Number of digest authentication challenges received from the FRITZ!Box.",
+	}, func() float64 {
+		return float64(client.Stats().AuthChallenges)
+	}))
+}
+
 type Metric struct {
+	Source  fritzboxmetrics.ServiceSource
 	Service string
 	Action  string
 	Result  string
 	OkValue string
+	// Output is the name of the output argument (e.g. NewUpstreamCurrRate) to export instead of Result
+	Output string
+	// Scale multiplies the value, e.g. to convert kbit/s into bit/s. 0 keeps the value.
+	Scale float64
+	// Optional metrics are skipped silently if the service is missing, e.g. DSL on cable models
+	Optional bool
+	// States exports one series per allowed value of the result, labelled with
+	// state, where the current value is 1. Desc needs the labels gateway and state.
+	States bool
 
 	Desc       *prometheus.Desc
 	MetricType prometheus.ValueType
 }
 
+// metrics are collected in order. Collect reuses the result of the previous
+// entry, so the entries of an action have to be consecutive.
 var metrics = []*Metric{
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetTotalPacketsReceived",
 		Result:  "TotalPacketsReceived",
@@ -62,6 +113,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.CounterValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetTotalPacketsSent",
 		Result:  "TotalPacketsSent",
@@ -74,6 +126,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.CounterValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetAddonInfos",
 		Result:  "X_AVM_DE_TotalBytesReceived64",
@@ -86,6 +139,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.CounterValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetAddonInfos",
 		Result:  "X_AVM_DE_TotalBytesSent64",
@@ -98,6 +152,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.CounterValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetAddonInfos",
 		Result:  "ByteSendRate",
@@ -110,6 +165,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetAddonInfos",
 		Result:  "ByteReceiveRate",
@@ -122,6 +178,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetCommonLinkProperties",
 		Result:  "Layer1UpstreamMaxBitRate",
@@ -134,6 +191,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetCommonLinkProperties",
 		Result:  "Layer1DownstreamMaxBitRate",
@@ -146,6 +204,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
 		Action:  "GetCommonLinkProperties",
 		Result:  "PhysicalLinkStatus",
@@ -159,6 +218,21 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
+		Service: "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
+		Action:  "GetCommonLinkProperties",
+		Result:  "PhysicalLinkStatus",
+		States:  true,
+		Desc: prometheus.NewDesc(
+			"gateway_wan_layer1_link_state",
+			"Status of physical link, 1 for the current state",
+			[]string{"gateway", "state"},
+			nil,
+		),
+		MetricType: prometheus.GaugeValue,
+	},
+	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANIPConnection:1",
 		Action:  "GetStatusInfo",
 		Result:  "ConnectionStatus",
@@ -172,6 +246,21 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceIGD,
+		Service: "urn:schemas-upnp-org:service:WANIPConnection:1",
+		Action:  "GetStatusInfo",
+		Result:  "ConnectionStatus",
+		States:  true,
+		Desc: prometheus.NewDesc(
+			"gateway_wan_connection_state",
+			"WAN connection status, 1 for the current state",
+			[]string{"gateway", "state"},
+			nil,
+		),
+		MetricType: prometheus.GaugeValue,
+	},
+	{
+		Source:  fritzboxmetrics.SourceIGD,
 		Service: "urn:schemas-upnp-org:service:WANIPConnection:1",
 		Action:  "GetStatusInfo",
 		Result:  "Uptime",
@@ -184,6 +273,7 @@ var metrics = []*Metric{
 		MetricType: prometheus.GaugeValue,
 	},
 	{
+		Source:  fritzboxmetrics.SourceTR64,
 		Service: "urn:dslforum-org:service:WLANConfiguration:1",
 		Action:  "GetTotalAssociations",
 		Result:  "TotalAssociations",
@@ -198,32 +288,108 @@ var metrics = []*Metric{
 }
 
 type FritzboxCollector struct {
-	Gateway  string
-	Port     uint16
-	Username string
-	Password string
+	Gateway string
+	Port    uint16
+	Client  *fritzboxmetrics.Client
+	Timeout time.Duration
+
+	CacheFile     string                   // Service descriptions are cached here if set
+	Events        *EventWatcher            // Subscribes to events of every loaded services tree if set
+	Session       *fritzboxmetrics.Session // Login to the web interface for values not available through TR-064
+	SmartHome     string                   // Source of the smart home metrics, disabled if empty
+	SmartHomeAINs []string                 // Smart home devices to look up via TR-064, all if empty
+	Hosts         bool                     // Export the hosts known to the FRITZ!Box
+	LAN           bool                     // Export the LAN ports
+	DOCSIS        bool                     // Export the DOCSIS channels of cable models
+	WLAN          bool                     // Export every WLAN and its clients
+	Mesh          bool                     // Export the mesh nodes and their links
+	VoIP          bool                     // Export the registration of the telephone numbers
 
 	sync.Mutex // protects Root
 	Root       *fritzboxmetrics.Root
 }
 
 // LoadServices tries to load the service information. Retries until success.
+// Afterwards the firmware version is checked periodically and the services
+// are reloaded after a firmware update.
+// If a cache file is configured, the cached services are used right away and
+// only reloaded if they don't belong to the current firmware of the FRITZ!Box.
 func (fc *FritzboxCollector) LoadServices() {
+	var current *fritzboxmetrics.Bundle
+
+	if fc.CacheFile != "" {
+		root, err := fc.loadCachedServices()
+		if err != nil {
+			fmt.Printf("cannot load cached services: %v\n", err)
+		} else {
+			fmt.Println("services loaded from cache")
+			fc.setRoot(root)
+			current = root.Bundle()
+		}
+	}
+
 	for {
-		root, err := fritzboxmetrics.LoadServices(fc.Gateway, fc.Port, fc.Username, fc.Password)
+		bundle, err := fc.refreshServices(context.Background(), current)
 		if err != nil {
-			fmt.Printf("cannot load services: %v\n", err)
+			fmt.Println(err)
 			// Sleep so long how often the metrics should be fetched
 			time.Sleep(serviceLoadRetryTime)
 			continue
 		}
+		current = bundle
+		time.Sleep(serviceRevalidateTime)
+	}
+}
 
-		fmt.Println("services loaded")
+// refreshServices loads the services unless current is the bundle of the
+// running firmware. A freshly loaded bundle is current by definition, so it
+// is only checked against the FRITZ!Box on the next call.
+// Returns the bundle of the services in use.
+func (fc *FritzboxCollector) refreshServices(ctx context.Context, current *fritzboxmetrics.Bundle) (*fritzboxmetrics.Bundle, error) {
+	if current != nil {
+		key, err := fc.Client.LoadBundleKey(ctx, fc.Gateway, fc.Port)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check firmware version: %w", err)
+		}
+		if key == current.BundleKey {
+			return current, nil
+		}
+		fmt.Printf("firmware changed from %q to %q, reloading services\n", current.FirmwareVersion, key.FirmwareVersion)
+	}
 
-		fc.Lock()
-		fc.Root = root
-		fc.Unlock()
-		return
+	root, err := fc.Client.LoadServices(ctx, fc.Gateway, fc.Port)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load services: %w", err)
+	}
+
+	fmt.Println("services loaded")
+
+	fc.setRoot(root)
+	bundle := root.Bundle()
+
+	if fc.CacheFile != "" {
+		if err := bundle.WriteFile(fc.CacheFile); err != nil {
+			fmt.Printf("cannot write service cache: %v\n", err)
+		}
+	}
+	return bundle, nil
+}
+
+func (fc *FritzboxCollector) loadCachedServices() (*fritzboxmetrics.Root, error) {
+	bundle, err := fritzboxmetrics.ReadBundleFile(fc.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+	return fc.Client.LoadServicesFromBundle(context.Background(), fc.Gateway, fc.Port, bundle)
+}
+
+func (fc *FritzboxCollector) setRoot(root *fritzboxmetrics.Root) {
+	fc.Lock()
+	fc.Root = root
+	fc.Unlock()
+
+	if fc.Events != nil {
+		fc.Events.Subscribe(root)
 	}
 }
 
@@ -231,6 +397,44 @@ func (fc *FritzboxCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, m := range metrics {
 		ch <- m.Desc
 	}
+	for _, d := range deviceDescs {
+		ch <- d
+	}
+	if fc.LAN {
+		for _, d := range lanPortDescs {
+			ch <- d
+		}
+	}
+	if fc.SmartHome != "" {
+		for _, d := range smartHomeDescs {
+			ch <- d
+		}
+	}
+	if fc.Hosts {
+		for _, d := range hostDescs {
+			ch <- d
+		}
+	}
+	if fc.DOCSIS {
+		for _, d := range docsisDescs {
+			ch <- d
+		}
+	}
+	if fc.WLAN {
+		for _, d := range wlanDescs {
+			ch <- d
+		}
+	}
+	if fc.Mesh {
+		for _, d := range meshDescs {
+			ch <- d
+		}
+	}
+	if fc.VoIP {
+		for _, d := range voipDescs {
+			ch <- d
+		}
+	}
 }
 
 func (fc *FritzboxCollector) Collect(ch chan<- prometheus.Metric) {
@@ -243,46 +447,85 @@ func (fc *FritzboxCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	var lastService string
+	// Abort the remaining calls of this scrape if the box doesn't respond
+	ctx, cancel := context.WithTimeout(context.Background(), fc.Timeout)
+	defer cancel()
+
+	var lastService *fritzboxmetrics.Service
 	var lastMethod string
+	var lastAction *fritzboxmetrics.Action
 	var lastResult fritzboxmetrics.Result
 
+	// Actions which failed are skipped for the remaining metrics of this scrape
+	type serviceAction struct {
+		service *fritzboxmetrics.Service
+		action  string
+	}
+	failed := make(map[serviceAction]bool)
+
 	for _, m := range metrics {
-		if m.Service != lastService || m.Action != lastMethod {
-			service, ok := root.Services[m.Service]
-			if !ok {
-				// TODO
-				fmt.Println("cannot find service", m.Service)
-				fmt.Println(root.Services)
+		service, ok := root.Service(m.Source, m.Service)
+		if !ok {
+			if !m.Optional {
+				log.Printf("cannot find service %s", m.Service)
+				collectErrors.Inc()
+			}
+			continue
+		}
+		if service != lastService || m.Action != lastMethod {
+			key := serviceAction{service, m.Action}
+			if failed[key] {
 				continue
 			}
+
 			action, ok := service.Actions[m.Action]
 			if !ok {
-				// TODO
-				fmt.Println("cannot find action", m.Action)
+				failed[key] = true
+				log.Printf("cannot find action %s %s", m.Service, m.Action)
+				collectErrors.Inc()
 				continue
 			}
 
 			var err error
-			lastResult, err = action.Call()
+			lastResult, err = action.CallContext(ctx)
 			if err != nil {
-				log.Printf("could not call action: %v", err)
-				collectErrors.Inc()
+				failed[key] = true
+				lastService = nil
+				reportCallError(m.Service, m.Action, err)
 				continue
 			}
+			lastService, lastMethod, lastAction = service, m.Action, action
 		}
 
-		val, ok := lastResult[m.Result]
+		var val interface{}
+		if m.Output != "" {
+			val, ok = lastAction.Output(lastResult, m.Output)
+		} else {
+			val, ok = lastResult[m.Result]
+		}
 		if !ok {
-			fmt.Println("result not found", m.Result)
+			log.Printf("result %s of %s %s not found", m.Result+m.Output, m.Service, m.Action)
 			collectErrors.Inc()
 			continue
 		}
 
+		if m.States {
+			variable, _ := lastService.StateVariable(m.Result)
+			if m.Output != "" {
+				variable = lastAction.ArgumentMap[m.Output].StateVariable
+			}
+			fc.collectStates(ch, m, variable, val)
+			continue
+		}
+
 		var floatval float64
 		switch tval := val.(type) {
 		case uint64:
 			floatval = float64(tval)
+		case int64:
+			floatval = float64(tval)
+		case float64:
+			floatval = tval
 		case bool:
 			if tval {
 				floatval = 1
@@ -296,22 +539,84 @@ func (fc *FritzboxCollector) Collect(ch chan<- prometheus.Metric) {
 				floatval = 0
 			}
 		default:
-			fmt.Println("unknown", val)
+			log.Printf("unsupported type %T of %s %s result %s", val, m.Service, m.Action, m.Result+m.Output)
 			collectErrors.Inc()
 			continue
 		}
+		if m.Scale != 0 {
+			floatval *= m.Scale
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			m.Desc,
+			m.MetricType,
+			floatval,
+			fc.Gateway,
+		)
+	}
 
+	fc.collectDeviceInfo(ctx, ch, root)
+	if fc.LAN {
+		fc.collectLANPorts(ctx, ch, root)
+	}
+	fc.collectSmartHome(ctx, ch, root)
+	fc.collectHosts(ctx, ch, root)
+	fc.collectDOCSIS(ctx, ch)
+	fc.collectWLAN(ctx, ch, root)
+	fc.collectMesh(ctx, ch, root)
+	fc.collectVoIP(ctx, ch, root)
+}
+
+// collectStates exports the state set of an enum valued result
+func (fc *FritzboxCollector) collectStates(ch chan<- prometheus.Metric, m *Metric, v *fritzboxmetrics.StateVariable, val interface{}) {
+	current := fmt.Sprint(val)
+	for _, state := range stateSet(v, current) {
+		var floatval float64
+		if state == current {
+			floatval = 1
+		}
 		ch <- prometheus.MustNewConstMetric(
 			m.Desc,
 			m.MetricType,
 			floatval,
 			fc.Gateway,
+			state,
 		)
 	}
 }
 
-func printToStdout(settings *Settings) error {
-	root, err := fritzboxmetrics.LoadServices(settings.FritzBox.IP, uint16(settings.FritzBox.Port), settings.FritzBox.UserName, settings.FritzBox.IP)
+// stateSet returns the allowed values of the state variable, which may be nil,
+// and the current value if it isn't one of them
+func stateSet(v *fritzboxmetrics.StateVariable, current string) []string {
+	if v == nil || len(v.AllowedValues) == 0 {
+		return []string{current}
+	}
+	if !v.IsAllowed(current) {
+		// Report values the SCPD doesn't know about as well
+		return append(v.AllowedValues[:len(v.AllowedValues):len(v.AllowedValues)], current)
+	}
+	return v.AllowedValues
+}
+
+// reportCallError logs a failed action call and counts it by its reason
+func reportCallError(service, action string, err error) {
+	collectErrors.Inc()
+
+	switch {
+	case errors.Is(err, fritzboxmetrics.ErrActionNotAuthorized):
+		log.Printf("not authorized to call %s %s, check the permissions of the user: %v", service, action, err)
+		actionErrors.WithLabelValues("not_authorized").Inc()
+	case errors.Is(err, fritzboxmetrics.ErrInvalidAction), errors.Is(err, fritzboxmetrics.ErrOptionalActionNotImplemented):
+		log.Printf("%s %s is not supported by this FRITZ!Box: %v", service, action, err)
+		actionErrors.WithLabelValues("not_supported").Inc()
+	default:
+		log.Printf("could not call action %s %s: %v", service, action, err)
+		actionErrors.WithLabelValues("other").Inc()
+	}
+}
+
+func printToStdout(client *fritzboxmetrics.Client, settings *Settings) error {
+	root, err := client.LoadServices(context.Background(), settings.FritzBox.IP, uint16(settings.FritzBox.Port))
 	if err != nil {
 		return fmt.Errorf("could not load UPnP service: %w", err)
 	}
@@ -337,14 +642,79 @@ func printToStdout(settings *Settings) error {
 	return nil
 }
 
+// record calls every get-only action of all service instances through
+// the recorder and writes the recording into dir.
+func record(recorder *fritzboxmetrics.Recorder, client *fritzboxmetrics.Client, settings *Settings, dir string) error {
+	root, err := client.LoadServices(context.Background(), settings.FritzBox.IP, uint16(settings.FritzBox.Port))
+	if err != nil {
+		return fmt.Errorf("could not load UPnP service: %w", err)
+	}
+
+	for _, s := range root.Instances {
+		for _, a := range s.Actions {
+			if !a.IsGetOnly() {
+				continue
+			}
+
+			// Failing calls are recorded as well, so they can be replayed
+			if _, err := a.Call(); err != nil {
+				log.Printf("could not call %s %s: %v", s.ServiceType, a.Name, err)
+			}
+		}
+	}
+
+	return recorder.WriteDir(dir)
+}
+
+func printDiscoveredDevices() error {
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+
+	devices, err := fritzboxmetrics.Discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range devices {
+		fmt.Printf("%s:%d\n", d.Host, d.Port)
+		fmt.Printf("  Server: %s\n", d.Server)
+		fmt.Printf("  Location: %s\n", d.Location)
+		fmt.Printf("  ST: %s\n", d.ST)
+		fmt.Printf("  USN: %s\n", d.USN)
+	}
+	return nil
+}
+
 type Settings struct {
 	Stdout     bool   `env:"STDOUT"`
 	ListenAddr string `env:"LISTEN_ADDR"`
-	FritzBox   struct {
+	Timeout    int    `env:"TIMEOUT"`
+	CacheFile  string `env:"CACHE_FILE"`
+	ReplayDir  string `env:"REPLAY_DIR"`
+	SmartHome  string `env:"SMARTHOME"`
+	Hosts      bool   `env:"HOSTS"`
+	LAN        bool   `env:"COLLECT_LAN"`
+	DOCSIS     bool   `env:"DOCSIS"`
+	WLAN       bool   `env:"WLAN"`
+	Mesh       bool   `env:"MESH"`
+	VoIP       bool   `env:"VOIP"`
+
+	SmartHomeAINs string `env:"SMARTHOME_AINS"`
+
+	EventListenAddr  string `env:"EVENT_LISTEN_ADDR"`
+	EventCallbackURL string `env:"EVENT_CALLBACK_URL"`
+
+	FritzBox struct {
 		IP       string `env:"IP"`
 		Port     int    `env:"PORT"`
 		UserName string `env:"USERNAME"`
 		Password string `env:"PASSWORD"`
+
+		WebURL string `env:"WEB_URL"`
+
+		TLS            bool   `env:"TLS"`
+		TLSCAFile      string `env:"TLS_CA_FILE"`
+		TLSFingerprint string `env:"TLS_FINGERPRINT"`
 	} `env:"FRITZ_BOX"`
 }
 
@@ -352,36 +722,180 @@ func main() {
 	settings := &Settings{}
 	flag.BoolVar(&settings.Stdout, "stdout", false, "print all available metrics to stdout")
 	flag.StringVar(&settings.ListenAddr, "listen-address", ":9133", "The address to listen on for HTTP requests.")
+	flag.StringVar(&settings.CacheFile, "cache-file", "", "File to cache the FRITZ!Box service descriptions in, to start without downloading them")
+	flag.StringVar(&settings.ReplayDir, "replay-dir", "", "Answer all requests from a recording made with the record command instead of the FRITZ!Box")
+	flag.StringVar(&settings.EventListenAddr, "event-listen-address", "", "The address to listen on for UPnP event notifications, disabled if empty")
+	flag.StringVar(&settings.EventCallbackURL, "event-callback-url", "", "The URL the FRITZ!Box sends event notifications to, derived from the local address if empty")
+	flag.StringVar(&settings.SmartHome, "smarthome", "", "Export smart home devices read from the given interface: aha or tr064, disabled if empty")
+	flag.StringVar(&settings.SmartHomeAINs, "smarthome-ains", "", "Comma separated AINs of the smart home devices to export with -smarthome tr064, all if empty")
+	flag.BoolVar(&settings.Hosts, "hosts", false, "Export the hosts known to the FRITZ!Box")
+	flag.BoolVar(&settings.LAN, "collect-lan", false, "Export the status and counters of the LAN ports")
+	flag.BoolVar(&settings.DOCSIS, "docsis", false, "Export the DOCSIS channels of cable models, read from the web interface")
+	flag.BoolVar(&settings.WLAN, "wlan", false, "Export every WLAN of the FRITZ!Box with its clients")
+	flag.BoolVar(&settings.Mesh, "mesh", false, "Export the mesh nodes and their links, and serve the mesh topology at /mesh")
+	flag.BoolVar(&settings.VoIP, "voip", false, "Export the registration status of the telephone numbers")
+	flag.IntVar(&settings.Timeout, "timeout", 10, "The timeout in seconds for each request to the FRITZ!Box")
 
 	flag.StringVar(&settings.FritzBox.IP, "gateway-address", "fritz.box", "The hostname or IP of the FRITZ!Box")
 	flag.IntVar(&settings.FritzBox.Port, "gateway-port", 49000, "The port of the FRITZ!Box UPnP service")
 	flag.StringVar(&settings.FritzBox.UserName, "username", "", "The user for the FRITZ!Box UPnP service")
 	flag.StringVar(&settings.FritzBox.Password, "password", "", "The password for the FRITZ!Box UPnP service")
+	flag.StringVar(&settings.FritzBox.WebURL, "web-url", "", "The URL of the FRITZ!Box web interface, http://<gateway-address> if empty")
+	flag.BoolVar(&settings.FritzBox.TLS, "tls", false, "Send TR-064 requests over HTTPS to the security port of the FRITZ!Box, the UPnP IGD requests stay on plain HTTP")
+	flag.StringVar(&settings.FritzBox.TLSCAFile, "tls-ca-file", "", "PEM file with the CA certificates to trust for the FRITZ!Box TLS endpoint")
+	flag.StringVar(&settings.FritzBox.TLSFingerprint, "tls-fingerprint", "", "SHA-256 fingerprint of the FRITZ!Box certificate to pin")
 	flag.Parse()
 
 	if err := envstruct.ApplyEnvVars(settings, "FRITZ_BOX_EXPORTER"); err != nil {
 		log.Fatalf("could not apply environment variables: %v", err)
 	}
 
+	switch settings.SmartHome {
+	case "", smartHomeAHA, smartHomeTR064:
+	default:
+		log.Fatalf("unknown smart home interface %q", settings.SmartHome)
+	}
+
+	clientOpts := fritzboxmetrics.ClientOptions{
+		Username: settings.FritzBox.UserName,
+		Password: settings.FritzBox.Password,
+		Timeout:  time.Duration(settings.Timeout) * time.Second,
+		UseTLS:   settings.FritzBox.TLS,
+	}
+	if settings.FritzBox.TLS {
+		tlsConfig, err := fritzboxmetrics.NewTLSConfig(settings.FritzBox.TLSCAFile, settings.FritzBox.TLSFingerprint)
+		if err != nil {
+			log.Fatalf("could not create TLS config: %v", err)
+		}
+		clientOpts.TLSConfig = tlsConfig
+	}
+
+	var recorder *fritzboxmetrics.Recorder
+	switch {
+	case flag.Arg(0) == "record":
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = clientOpts.TLSConfig
+		recorder = fritzboxmetrics.NewRecorder(fritzboxmetrics.RecorderOptions{
+			Transport: transport,
+			Redact:    []string{settings.FritzBox.UserName, settings.FritzBox.Password},
+		})
+		clientOpts.Transport = recorder
+	case settings.ReplayDir != "":
+		replay, err := fritzboxmetrics.NewReplayTransport(settings.ReplayDir)
+		if err != nil {
+			log.Fatalf("could not load recording: %v", err)
+		}
+		clientOpts.Transport = replay
+	}
+	client := fritzboxmetrics.NewClient(clientOpts)
+
+	if flag.Arg(0) == "record" {
+		if flag.Arg(1) == "" {
+			log.Fatal("usage: exporter [flags] record <directory>")
+		}
+		if err := record(recorder, client, settings, flag.Arg(1)); err != nil {
+			log.Fatalf("could not record: %v", err)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "discover" {
+		if err := printDiscoveredDevices(); err != nil {
+			log.Fatalf("could not discover devices: %v", err)
+		}
+		return
+	}
+
 	if settings.Stdout {
-		if err := printToStdout(settings); err != nil {
+		if err := printToStdout(client, settings); err != nil {
 			log.Fatalf("could not print metrics to stdout: %v", err)
 		}
 		return
 	}
 
 	collector := &FritzboxCollector{
-		Gateway:  settings.FritzBox.IP,
-		Port:     uint16(settings.FritzBox.Port),
-		Username: settings.FritzBox.UserName,
-		Password: settings.FritzBox.Password,
+		Gateway: settings.FritzBox.IP,
+		Port:    uint16(settings.FritzBox.Port),
+		Client:  client,
+		Timeout: scrapeTimeout,
+
+		CacheFile: settings.CacheFile,
+		SmartHome: settings.SmartHome,
+		Hosts:     settings.Hosts,
+		LAN:       settings.LAN,
+		DOCSIS:    settings.DOCSIS,
+		WLAN:      settings.WLAN,
+		Mesh:      settings.Mesh,
+		VoIP:      settings.VoIP,
+	}
+	for _, ain := range strings.Split(settings.SmartHomeAINs, ",") {
+		if ain = strings.TrimSpace(ain); ain != "" {
+			collector.SmartHomeAINs = append(collector.SmartHomeAINs, ain)
+		}
+	}
+
+	webURL := settings.FritzBox.WebURL
+	if webURL == "" {
+		webURL = "http://" + settings.FritzBox.IP
+	}
+	collector.Session = client.NewSession(webURL)
+
+	if settings.EventListenAddr != "" {
+		manager, err := fritzboxmetrics.NewSubscriptionManager(client, fritzboxmetrics.SubscriptionOptions{
+			ListenAddr:  settings.EventListenAddr,
+			CallbackURL: settings.EventCallbackURL,
+		})
+		if err != nil {
+			log.Fatalf("could not start event subscriptions: %v", err)
+		}
+		collector.Events = &EventWatcher{
+			Gateway: settings.FritzBox.IP,
+			Manager: manager,
+		}
+		go collector.Events.Run()
+
+		prometheus.MustRegister(wanConnectionStatusChanges)
+		prometheus.MustRegister(wanExternalIPChanges)
 	}
 
 	go collector.LoadServices()
 
 	prometheus.MustRegister(collector)
 	prometheus.MustRegister(collectErrors)
+	prometheus.MustRegister(actionErrors)
+	registerClientStats(client)
 
 	http.Handle("/metrics", promhttp.Handler())
-	log.Fatal(http.ListenAndServe(settings.ListenAddr, nil))
+	if settings.Mesh {
+		http.HandleFunc("/mesh", collector.serveMesh)
+	}
+	server := &http.Server{Addr: settings.ListenAddr}
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("could not shut down HTTP server: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+	// Don't leave sessions and subscriptions behind on the FRITZ!Box
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := collector.Session.Close(ctx); err != nil {
+		log.Printf("could not close web session: %v", err)
+	}
+	if collector.Events != nil {
+		if err := collector.Events.Manager.Close(ctx); err != nil {
+			log.Printf("could not cancel event subscriptions: %v", err)
+		}
+	}
 }
@@ -0,0 +1,270 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const (
+	testGateway  = "fritz.box"
+	testUsername = "exporter"
+	testPassword = "secret"
+)
+
+// newTestCollector starts a fake FRITZ!Box and returns a collector with its services loaded
+func newTestCollector(t *testing.T) (*fritzboxtest.Server, *FritzboxCollector) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	t.Cleanup(s.Close)
+
+	client := fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: testUsername, Password: testPassword})
+	root, err := client.LoadServices(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+
+	return s, &FritzboxCollector{
+		Gateway: testGateway,
+		Port:    s.Port(),
+		Client:  client,
+		Timeout: 5 * time.Second,
+		Root:    root,
+	}
+}
+
+// assertMetrics scrapes the collector and compares the series of the given
+// metrics with the expected text exposition
+func assertMetrics(t *testing.T, fc *FritzboxCollector, expected string, names ...string) {
+	t.Helper()
+	if err := testutil.CollectAndCompare(fc, strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+}
+
+// assertScrape checks that the collector can be registered and scraped without errors
+func assertScrape(t *testing.T, fc *FritzboxCollector) {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(fc); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := reg.Gather(); err != nil {
+		t.Errorf("Gather: %v", err)
+	}
+}
+
+func TestMetricsGroupedByAction(t *testing.T) {
+	key := func(m *Metric) string {
+		return string(m.Source) + " " + m.Service + "#" + m.Action
+	}
+	done := make(map[string]bool)
+	for i, m := range metrics {
+		if i > 0 && key(m) == key(metrics[i-1]) {
+			continue
+		}
+		if done[key(m)] {
+			t.Errorf("%s is called again for %s, move it next to the other metrics of the action", key(m), m.Desc)
+		}
+		done[key(m)] = true
+	}
+}
+
+func TestCollect(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse("urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1", "GetAddonInfos", map[string]string{
+		"NewByteSendRate":                  "1200",
+		"NewByteReceiveRate":               "34000",
+		"NewX_AVM_DE_TotalBytesSent64":     "5000000000",
+		"NewX_AVM_DE_TotalBytesReceived64": "",
+	})
+	s.SetResponse("urn:schemas-upnp-org:service:WANIPConnection:1", "GetStatusInfo", map[string]string{
+		"NewConnectionStatus":    "Connecting",
+		"NewLastConnectionError": "ERROR_NONE",
+		"NewUptime":              "0",
+	})
+
+	assertMetrics(t, fc, `
+# HELP gateway_wan_bytes_received bytes received on gateway WAN interface
+# TYPE gateway_wan_bytes_received counter
+gateway_wan_bytes_received{gateway="fritz.box"} 0
+# HELP gateway_wan_bytes_send_rate byte send rate on gateway WAN interface
+# TYPE gateway_wan_bytes_send_rate gauge
+gateway_wan_bytes_send_rate{gateway="fritz.box"} 1200
+# HELP gateway_wan_bytes_sent bytes sent on gateway WAN interface
+# TYPE gateway_wan_bytes_sent counter
+gateway_wan_bytes_sent{gateway="fritz.box"} 5e+09
+# HELP gateway_wan_connection_state WAN connection status, 1 for the current state
+# TYPE gateway_wan_connection_state gauge
+gateway_wan_connection_state{gateway="fritz.box",state="Authenticating"} 0
+gateway_wan_connection_state{gateway="fritz.box",state="Connected"} 0
+gateway_wan_connection_state{gateway="fritz.box",state="Connecting"} 1
+gateway_wan_connection_state{gateway="fritz.box",state="Disconnected"} 0
+gateway_wan_connection_state{gateway="fritz.box",state="Disconnecting"} 0
+gateway_wan_connection_state{gateway="fritz.box",state="PendingDisconnect"} 0
+gateway_wan_connection_state{gateway="fritz.box",state="Unconfigured"} 0
+# HELP gateway_wan_connection_status WAN connection status (Connected = 1)
+# TYPE gateway_wan_connection_status gauge
+gateway_wan_connection_status{gateway="fritz.box"} 0
+`, "gateway_wan_bytes_received", "gateway_wan_bytes_send_rate", "gateway_wan_bytes_sent",
+		"gateway_wan_connection_state", "gateway_wan_connection_status")
+}
+
+func TestCollectFailingAction(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse("urn:schemas-upnp-org:service:WANIPConnection:1", "GetStatusInfo", map[string]string{
+		"NewConnectionStatus": "Connected",
+		"NewUptime":           "3600",
+	})
+	s.InjectFault("urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1", "", fritzboxtest.Fault{UPnPErrorCode: 606, UPnPErrorDescription: "Action not authorized"})
+
+	before := testutil.ToFloat64(actionErrors.WithLabelValues("not_authorized"))
+	assertMetrics(t, fc, `
+# HELP gateway_wan_connection_uptime_seconds WAN connection uptime
+# TYPE gateway_wan_connection_uptime_seconds gauge
+gateway_wan_connection_uptime_seconds{gateway="fritz.box"} 3600
+`, "gateway_wan_bytes_sent", "gateway_wan_connection_uptime_seconds")
+
+	if got := testutil.ToFloat64(actionErrors.WithLabelValues("not_authorized")) - before; got != 4 {
+		t.Errorf("got %v failed calls, want one per action", got)
+	}
+	for _, action := range []string{"GetTotalPacketsReceived", "GetTotalPacketsSent", "GetAddonInfos", "GetCommonLinkProperties"} {
+		if got := s.Calls("urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1", action); got != 1 {
+			t.Errorf("%s called %d times, want once per scrape", action, got)
+		}
+	}
+}
+
+func TestCollectWithoutServices(t *testing.T) {
+	fc := &FritzboxCollector{Gateway: testGateway, Timeout: time.Second}
+	if n := testutil.CollectAndCount(fc); n != 0 {
+		t.Errorf("got %d metrics before the services are loaded, want none", n)
+	}
+}
+
+func TestCollectTimeout(t *testing.T) {
+	s, fc := newTestCollector(t)
+	fc.Timeout = 100 * time.Millisecond
+	s.InjectFault("urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1", "", fritzboxtest.Fault{Delay: 5 * time.Second})
+
+	start := time.Now()
+	assertScrape(t, fc)
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("scrape took %v, want it to be aborted after the timeout", d)
+	}
+}
+
+func TestCollectMissingResult(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse("urn:schemas-upnp-org:service:WANIPConnection:1", "GetStatusInfo", map[string]string{
+		"NewConnectionStatus": "Connected",
+	})
+
+	before := testutil.ToFloat64(collectErrors)
+	assertMetrics(t, fc, `
+# HELP gateway_wan_connection_status WAN connection status (Connected = 1)
+# TYPE gateway_wan_connection_status gauge
+gateway_wan_connection_status{gateway="fritz.box"} 1
+`, "gateway_wan_connection_status", "gateway_wan_connection_uptime_seconds")
+
+	if testutil.ToFloat64(collectErrors) == before {
+		t.Error("missing results are not counted")
+	}
+}
+
+func TestRefreshServices(t *testing.T) {
+	s, fc := newTestCollector(t)
+	fc.Gateway = s.Host()
+	fc.Root = nil
+	fc.CacheFile = filepath.Join(t.TempDir(), "services.json")
+	ctx := context.Background()
+
+	current, err := fc.refreshServices(ctx, nil)
+	if err != nil {
+		t.Fatalf("refreshServices: %v", err)
+	}
+	if fc.Root == nil || fc.Root.Bundle() != current {
+		t.Fatal("loaded services are not in use")
+	}
+	assertCachedKey(t, fc.CacheFile, current.BundleKey)
+
+	// Unchanged firmware, only tr64desc.xml is fetched to compare the key
+	root := fc.Root
+	fetches := s.Fetches("/tr64desc.xml")
+	loads := s.Fetches("/igddesc.xml")
+	bundle, err := fc.refreshServices(ctx, current)
+	if err != nil {
+		t.Fatalf("refreshServices: %v", err)
+	}
+	if bundle != current || fc.Root != root {
+		t.Error("services reloaded without a firmware update")
+	}
+	if got := s.Fetches("/tr64desc.xml") - fetches; got != 1 {
+		t.Errorf("tr64desc.xml fetched %d times, want 1", got)
+	}
+	if s.Fetches("/igddesc.xml") != loads {
+		t.Error("services fetched again without a firmware update")
+	}
+
+	s.SetDocument("/tr64desc.xml", strings.Replace(current.Documents["/tr64desc.xml"], "154.07.29", "154.07.50", 1))
+	bundle, err = fc.refreshServices(ctx, current)
+	if err != nil {
+		t.Fatalf("refreshServices: %v", err)
+	}
+	if bundle.FirmwareVersion != "154.07.50" || bundle.UDN != current.UDN {
+		t.Errorf("got key %+v after the firmware update", bundle.BundleKey)
+	}
+	if fc.Root == root || fc.Root.Bundle() != bundle {
+		t.Error("services not reloaded after the firmware update")
+	}
+	if s.Fetches("/igddesc.xml") != loads+1 {
+		t.Error("services not fetched again after the firmware update")
+	}
+	assertCachedKey(t, fc.CacheFile, bundle.BundleKey)
+}
+
+func TestRefreshServicesUnreachable(t *testing.T) {
+	s, fc := newTestCollector(t)
+	fc.Gateway = s.Host()
+	current := fc.Root.Bundle()
+	s.Close()
+
+	if _, err := fc.refreshServices(context.Background(), current); err == nil {
+		t.Error("checking the firmware of an unreachable FRITZ!Box succeeded")
+	}
+	if fc.Root.Bundle() != current {
+		t.Error("services replaced although the check failed")
+	}
+}
+
+// assertCachedKey checks the key of the bundle in the cache file
+func assertCachedKey(t *testing.T, path string, want fritzboxmetrics.BundleKey) {
+	t.Helper()
+	bundle, err := fritzboxmetrics.ReadBundleFile(path)
+	if err != nil {
+		t.Fatalf("ReadBundleFile: %v", err)
+	}
+	if bundle.BundleKey != want {
+		t.Errorf("got cached key %+v, want %+v", bundle.BundleKey, want)
+	}
+}
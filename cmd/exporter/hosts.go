@@ -0,0 +1,118 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"log"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/hosts"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const hostListPathAction = "X_AVM-DE_GetHostListPath"
+
+var hostLabels = []string{"gateway", "mac", "ip", "hostname", "interface_type"}
+
+var (
+	hostActive = prometheus.NewDesc(
+		"fritzbox_host_active",
+		"Host is active in the network (1) or only known to the FRITZ!Box (0)",
+		hostLabels,
+		nil,
+	)
+	hostLinkSpeed = prometheus.NewDesc(
+		"fritzbox_host_link_speed_bits_per_second",
+		"Link speed of the host as reported by the FRITZ!Box",
+		hostLabels,
+		nil,
+	)
+
+	hostDescs = []*prometheus.Desc{hostActive, hostLinkSpeed}
+)
+
+// collectHosts exports the hosts known to the FRITZ!Box. The host list is
+// downloaded at once if the FRITZ!Box supports it, otherwise every host is read by its index.
+func (fc *FritzboxCollector) collectHosts(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	if !fc.Hosts {
+		return
+	}
+
+	client, err := hosts.New(root, fritzboxmetrics.SourceTR64)
+	if err != nil {
+		log.Printf("could not collect hosts: %v", err)
+		collectErrors.Inc()
+		return
+	}
+
+	var list []hosts.Host
+	bulk := false
+	if _, ok := client.Service.Actions[hostListPathAction]; ok {
+		list, err = client.HostList(ctx)
+		if err != nil {
+			reportCallError(hosts.ServiceType, hostListPathAction, err)
+		}
+		bulk = err == nil
+	}
+	if !bulk {
+		list, err = genericHostList(ctx, client)
+		if err != nil {
+			reportCallError(hosts.ServiceType, "GetGenericHostEntry", err)
+			return
+		}
+	}
+
+	seen := make(map[string]bool, len(list))
+	for _, h := range list {
+		if h.MACAddress == "" || seen[h.MACAddress] {
+			// Entries without MAC address can't be told apart, and a host
+			// may be listed twice, e.g. while it moves between LAN and WLAN
+			continue
+		}
+		seen[h.MACAddress] = true
+
+		labels := []string{fc.Gateway, h.MACAddress, h.IPAddress, h.HostName, h.InterfaceType}
+		ch <- prometheus.MustNewConstMetric(hostActive, prometheus.GaugeValue, boolToFloat(h.Active), labels...)
+		if h.Speed > 0 {
+			ch <- prometheus.MustNewConstMetric(hostLinkSpeed, prometheus.GaugeValue, float64(h.Speed)*1e6, labels...)
+		}
+	}
+}
+
+// genericHostList reads the hosts one by one. The link speed isn't available this way.
+func genericHostList(ctx context.Context, client *hosts.Client) ([]hosts.Host, error) {
+	n, err := client.GetHostNumberOfEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]hosts.Host, 0, n.HostNumberOfEntries)
+	for index := uint16(0); index < n.HostNumberOfEntries; index++ {
+		entry, err := client.GetGenericHostEntry(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, hosts.Host{
+			Index:         int(index),
+			IPAddress:     entry.IPAddress,
+			MACAddress:    entry.MACAddress,
+			Active:        entry.Active,
+			HostName:      entry.HostName,
+			InterfaceType: entry.InterfaceType,
+		})
+	}
+	return list, nil
+}
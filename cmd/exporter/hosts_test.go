@@ -0,0 +1,101 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/hosts"
+)
+
+var hostMetricNames = []string{"fritzbox_host_active", "fritzbox_host_link_speed_bits_per_second"}
+
+func TestCollectHosts(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetDocument("/devicehostlist.lua", fritzboxtest.HostList)
+	s.SetResponse(hosts.ServiceType, hostListPathAction, map[string]string{
+		"NewX_AVM-DE_HostListPath": "/devicehostlist.lua",
+	})
+	fc.Hosts = true
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_host_active Host is active in the network (1) or only known to the FRITZ!Box (0)
+# TYPE fritzbox_host_active gauge
+fritzbox_host_active{gateway="fritz.box",hostname="phone",interface_type="802.11",ip="192.168.178.21",mac="02:00:00:00:00:02"} 0
+fritzbox_host_active{gateway="fritz.box",hostname="workstation",interface_type="Ethernet",ip="192.168.178.20",mac="02:00:00:00:00:01"} 1
+# HELP fritzbox_host_link_speed_bits_per_second Link speed of the host as reported by the FRITZ!Box
+# TYPE fritzbox_host_link_speed_bits_per_second gauge
+fritzbox_host_link_speed_bits_per_second{gateway="fritz.box",hostname="workstation",interface_type="Ethernet",ip="192.168.178.20",mac="02:00:00:00:00:01"} 1e+09
+`, hostMetricNames...)
+}
+
+func TestCollectHostsGeneric(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.InjectFault(hosts.ServiceType, hostListPathAction, fritzboxtest.Fault{UPnPErrorCode: 820, UPnPErrorDescription: "Internal Error"})
+	s.SetResponse(hosts.ServiceType, "GetHostNumberOfEntries", map[string]string{
+		"NewHostNumberOfEntries": "2",
+	})
+	s.Handle(hosts.ServiceType, "GetGenericHostEntry", func(args map[string]string) (map[string]string, error) {
+		if args["NewIndex"] == "0" {
+			return map[string]string{
+				"NewIPAddress":          "192.168.178.20",
+				"NewAddressSource":      "DHCP",
+				"NewLeaseTimeRemaining": "864000",
+				"NewMACAddress":         "02:00:00:00:00:01",
+				"NewInterfaceType":      "Ethernet",
+				"NewActive":             "1",
+				"NewHostName":           "workstation",
+			}, nil
+		}
+		// Entries without MAC address are skipped
+		return map[string]string{
+			"NewIPAddress":          "",
+			"NewAddressSource":      "",
+			"NewLeaseTimeRemaining": "0",
+			"NewMACAddress":         "",
+			"NewInterfaceType":      "",
+			"NewActive":             "0",
+			"NewHostName":           "unknown",
+		}, nil
+	})
+	fc.Hosts = true
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_host_active Host is active in the network (1) or only known to the FRITZ!Box (0)
+# TYPE fritzbox_host_active gauge
+fritzbox_host_active{gateway="fritz.box",hostname="workstation",interface_type="Ethernet",ip="192.168.178.20",mac="02:00:00:00:00:01"} 1
+`, hostMetricNames...)
+}
+
+func TestCollectHostsDuplicate(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetDocument("/devicehostlist.lua", `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<Item><Index>1</Index><IPAddress>192.168.178.20</IPAddress><MACAddress>02:00:00:00:00:01</MACAddress><Active>1</Active><HostName>workstation</HostName><InterfaceType>Ethernet</InterfaceType><X_AVM-DE_Speed>1000</X_AVM-DE_Speed></Item>
+<Item><Index>2</Index><IPAddress>192.168.178.20</IPAddress><MACAddress>02:00:00:00:00:01</MACAddress><Active>1</Active><HostName>workstation</HostName><InterfaceType>Ethernet</InterfaceType><X_AVM-DE_Speed>100</X_AVM-DE_Speed></Item>
+</List>`)
+	s.SetResponse(hosts.ServiceType, hostListPathAction, map[string]string{
+		"NewX_AVM-DE_HostListPath": "/devicehostlist.lua",
+	})
+	fc.Hosts = true
+
+	assertScrape(t, fc)
+	assertMetrics(t, fc, `
+# HELP fritzbox_host_link_speed_bits_per_second Link speed of the host as reported by the FRITZ!Box
+# TYPE fritzbox_host_link_speed_bits_per_second gauge
+fritzbox_host_link_speed_bits_per_second{gateway="fritz.box",hostname="workstation",interface_type="Ethernet",ip="192.168.178.20",mac="02:00:00:00:00:01"} 1e+09
+`, "fritzbox_host_link_speed_bits_per_second")
+}
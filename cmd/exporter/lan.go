@@ -0,0 +1,142 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/lanethernetinterfaceconfig"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var lanPortLabels = []string{"gateway", "port"}
+
+var (
+	lanPortStatus = prometheus.NewDesc(
+		"fritzbox_lan_port_status",
+		"Link status of the LAN port, 1 for the current state",
+		append(lanPortLabels, "state"),
+		nil,
+	)
+	lanPortEnabled = prometheus.NewDesc(
+		"fritzbox_lan_port_enabled",
+		"LAN port is enabled (1) or disabled (0)",
+		lanPortLabels,
+		nil,
+	)
+	lanPortMaxBitRate = prometheus.NewDesc(
+		"fritzbox_lan_port_max_bit_rate_bits_per_second",
+		"Configured maximum bit rate of the LAN port, missing if negotiated automatically",
+		lanPortLabels,
+		nil,
+	)
+	lanPortDuplexMode = prometheus.NewDesc(
+		"fritzbox_lan_port_duplex_mode",
+		"Duplex mode of the LAN port, 1 for the current mode",
+		append(lanPortLabels, "state"),
+		nil,
+	)
+	lanPortBytesSent = prometheus.NewDesc(
+		"fritzbox_lan_port_bytes_sent_total",
+		"Bytes sent on the LAN port",
+		lanPortLabels,
+		nil,
+	)
+	lanPortBytesReceived = prometheus.NewDesc(
+		"fritzbox_lan_port_bytes_received_total",
+		"Bytes received on the LAN port",
+		lanPortLabels,
+		nil,
+	)
+	lanPortPacketsSent = prometheus.NewDesc(
+		"fritzbox_lan_port_packets_sent_total",
+		"Packets sent on the LAN port",
+		lanPortLabels,
+		nil,
+	)
+	lanPortPacketsReceived = prometheus.NewDesc(
+		"fritzbox_lan_port_packets_received_total",
+		"Packets received on the LAN port",
+		lanPortLabels,
+		nil,
+	)
+
+	lanPortDescs = []*prometheus.Desc{
+		lanPortStatus,
+		lanPortEnabled,
+		lanPortMaxBitRate,
+		lanPortDuplexMode,
+		lanPortBytesSent,
+		lanPortBytesReceived,
+		lanPortPacketsSent,
+		lanPortPacketsReceived,
+	}
+)
+
+// collectLANPorts exports every LANEthernetInterfaceConfig instance, labelled
+// with its instance number as port
+func (fc *FritzboxCollector) collectLANPorts(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	for _, service := range root.ServicesByType(fritzboxmetrics.SourceTR64, lanethernetinterfaceconfig.ServiceType) {
+		client := &lanethernetinterfaceconfig.Client{Service: service}
+		labels := []string{fc.Gateway, strconv.Itoa(service.Instance())}
+		gauge := func(desc *prometheus.Desc, val float64, extra ...string) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, val, append(labels, extra...)...)
+		}
+		counter := func(desc *prometheus.Desc, val uint64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(val), labels...)
+		}
+
+		info, err := client.GetInfo(ctx)
+		if err != nil {
+			reportCallError(service.ServiceType, "GetInfo", err)
+			continue
+		}
+		for _, state := range stateSet(outputVariable(service, "GetInfo", "NewStatus"), info.Status) {
+			gauge(lanPortStatus, boolToFloat(state == info.Status), state)
+		}
+		gauge(lanPortEnabled, boolToFloat(info.Enable))
+		if mbits, err := strconv.ParseFloat(info.MaxBitRate, 64); err == nil {
+			gauge(lanPortMaxBitRate, mbits*1e6)
+		}
+		for _, state := range stateSet(outputVariable(service, "GetInfo", "NewDuplexMode"), info.DuplexMode) {
+			gauge(lanPortDuplexMode, boolToFloat(state == info.DuplexMode), state)
+		}
+
+		stats, err := client.GetStatistics(ctx)
+		if err != nil {
+			reportCallError(service.ServiceType, "GetStatistics", err)
+			continue
+		}
+		counter(lanPortBytesSent, stats.BytesSent)
+		counter(lanPortBytesReceived, stats.BytesReceived)
+		counter(lanPortPacketsSent, stats.PacketsSent)
+		counter(lanPortPacketsReceived, stats.PacketsReceived)
+	}
+}
+
+// outputVariable returns the state variable of an output argument, nil if it's unknown
+func outputVariable(service *fritzboxmetrics.Service, action, output string) *fritzboxmetrics.StateVariable {
+	a, ok := service.Actions[action]
+	if !ok {
+		return nil
+	}
+	arg, ok := a.ArgumentMap[output]
+	if !ok {
+		return nil
+	}
+	return arg.StateVariable
+}
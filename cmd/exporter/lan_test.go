@@ -0,0 +1,93 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/lanethernetinterfaceconfig"
+)
+
+func TestCollectLANPorts(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.HandleControl("/upnp/control/lanethernetifcfg1", "GetInfo", func(map[string]string) (map[string]string, error) {
+		return map[string]string{"NewEnable": "1", "NewStatus": "Up", "NewMACAddress": "02:00:00:00:00:10", "NewMaxBitRate": "100", "NewDuplexMode": "Full"}, nil
+	})
+	s.HandleControl("/upnp/control/lanethernetifcfg1", "GetStatistics", func(map[string]string) (map[string]string, error) {
+		return map[string]string{"NewBytesSent": "5000000000", "NewBytesReceived": "1200", "NewPacketsSent": "3000000", "NewPacketsReceived": "20"}, nil
+	})
+	s.HandleControl("/upnp/control/lanethernetifcfg2", "GetInfo", func(map[string]string) (map[string]string, error) {
+		return map[string]string{"NewEnable": "1", "NewStatus": "NoLink", "NewMACAddress": "02:00:00:00:00:10", "NewMaxBitRate": "Auto", "NewDuplexMode": "Auto"}, nil
+	})
+	s.HandleControl("/upnp/control/lanethernetifcfg2", "GetStatistics", func(map[string]string) (map[string]string, error) {
+		return nil, &fritzboxtest.Fault{UPnPErrorCode: 820, UPnPErrorDescription: "Internal Error"}
+	})
+	fc.LAN = true
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_lan_port_bytes_received_total Bytes received on the LAN port
+# TYPE fritzbox_lan_port_bytes_received_total counter
+fritzbox_lan_port_bytes_received_total{gateway="fritz.box",port="1"} 1200
+# HELP fritzbox_lan_port_bytes_sent_total Bytes sent on the LAN port
+# TYPE fritzbox_lan_port_bytes_sent_total counter
+fritzbox_lan_port_bytes_sent_total{gateway="fritz.box",port="1"} 5e+09
+# HELP fritzbox_lan_port_duplex_mode Duplex mode of the LAN port, 1 for the current mode
+# TYPE fritzbox_lan_port_duplex_mode gauge
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="1",state="Auto"} 0
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="1",state="Full"} 1
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="1",state="Half"} 0
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="2",state="Auto"} 1
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="2",state="Full"} 0
+fritzbox_lan_port_duplex_mode{gateway="fritz.box",port="2",state="Half"} 0
+# HELP fritzbox_lan_port_enabled LAN port is enabled (1) or disabled (0)
+# TYPE fritzbox_lan_port_enabled gauge
+fritzbox_lan_port_enabled{gateway="fritz.box",port="1"} 1
+fritzbox_lan_port_enabled{gateway="fritz.box",port="2"} 1
+# HELP fritzbox_lan_port_max_bit_rate_bits_per_second Configured maximum bit rate of the LAN port, missing if negotiated automatically
+# TYPE fritzbox_lan_port_max_bit_rate_bits_per_second gauge
+fritzbox_lan_port_max_bit_rate_bits_per_second{gateway="fritz.box",port="1"} 1e+08
+# HELP fritzbox_lan_port_packets_received_total Packets received on the LAN port
+# TYPE fritzbox_lan_port_packets_received_total counter
+fritzbox_lan_port_packets_received_total{gateway="fritz.box",port="1"} 20
+# HELP fritzbox_lan_port_packets_sent_total Packets sent on the LAN port
+# TYPE fritzbox_lan_port_packets_sent_total counter
+fritzbox_lan_port_packets_sent_total{gateway="fritz.box",port="1"} 3e+06
+# HELP fritzbox_lan_port_status Link status of the LAN port, 1 for the current state
+# TYPE fritzbox_lan_port_status gauge
+fritzbox_lan_port_status{gateway="fritz.box",port="1",state="Disabled"} 0
+fritzbox_lan_port_status{gateway="fritz.box",port="1",state="Error"} 0
+fritzbox_lan_port_status{gateway="fritz.box",port="1",state="NoLink"} 0
+fritzbox_lan_port_status{gateway="fritz.box",port="1",state="Up"} 1
+fritzbox_lan_port_status{gateway="fritz.box",port="2",state="Disabled"} 0
+fritzbox_lan_port_status{gateway="fritz.box",port="2",state="Error"} 0
+fritzbox_lan_port_status{gateway="fritz.box",port="2",state="NoLink"} 1
+fritzbox_lan_port_status{gateway="fritz.box",port="2",state="Up"} 0
+`, "fritzbox_lan_port_bytes_received_total", "fritzbox_lan_port_bytes_sent_total", "fritzbox_lan_port_duplex_mode",
+		"fritzbox_lan_port_enabled", "fritzbox_lan_port_max_bit_rate_bits_per_second", "fritzbox_lan_port_packets_received_total",
+		"fritzbox_lan_port_packets_sent_total", "fritzbox_lan_port_status")
+}
+
+func TestCollectLANPortsDisabled(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetResponse(lanethernetinterfaceconfig.ServiceType, "GetInfo", map[string]string{
+		"NewEnable": "1", "NewStatus": "Up", "NewMACAddress": "02:00:00:00:00:10", "NewMaxBitRate": "100", "NewDuplexMode": "Full",
+	})
+
+	assertMetrics(t, fc, "", "fritzbox_lan_port_status", "fritzbox_lan_port_enabled")
+	if s.Calls(lanethernetinterfaceconfig.ServiceType, "GetInfo") != 0 {
+		t.Error("LAN ports were read without -collect-lan")
+	}
+}
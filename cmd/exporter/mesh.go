@@ -0,0 +1,378 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/hosts"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The nodes are labelled with their MAC address, too, as repeaters may have the same name
+var (
+	meshLinkLabels      = []string{"gateway", "node_1", "node_1_mac", "interface_1", "node_2", "node_2_mac", "interface_2", "type"}
+	meshInterfaceLabels = []string{"gateway", "node", "node_mac", "interface", "type"}
+)
+
+var (
+	meshNodeInfo = prometheus.NewDesc(
+		"fritzbox_mesh_node_info",
+		"Mesh node (FRITZ!Box or repeater) with its role master or slave, always 1",
+		[]string{"gateway", "node", "model", "mac", "role", "firmware_version"},
+		nil,
+	)
+	meshLinkConnected = prometheus.NewDesc(
+		"fritzbox_mesh_link_connected",
+		"Link between two mesh nodes is connected (1) or not (0)",
+		meshLinkLabels,
+		nil,
+	)
+	meshLinkDataRate = prometheus.NewDesc(
+		"fritzbox_mesh_link_data_rate_bits_per_second",
+		"Current data rate of the link, direction as seen from node_1",
+		append(meshLinkLabels, "direction"),
+		nil,
+	)
+	meshLinkMaxDataRate = prometheus.NewDesc(
+		"fritzbox_mesh_link_max_data_rate_bits_per_second",
+		"Maximum data rate of the link, direction as seen from node_1",
+		append(meshLinkLabels, "direction"),
+		nil,
+	)
+	meshInterfaceConnected = prometheus.NewDesc(
+		"fritzbox_mesh_interface_connected",
+		"Interface of a mesh node has a connected link (1) or not (0)",
+		meshInterfaceLabels,
+		nil,
+	)
+	meshInterfaceDataRate = prometheus.NewDesc(
+		"fritzbox_mesh_interface_data_rate_bits_per_second",
+		"Current data rate of all links of the interface of a mesh node",
+		append(meshInterfaceLabels, "direction"),
+		nil,
+	)
+	meshInterfaceMaxDataRate = prometheus.NewDesc(
+		"fritzbox_mesh_interface_max_data_rate_bits_per_second",
+		"Maximum data rate of all links of the interface of a mesh node",
+		append(meshInterfaceLabels, "direction"),
+		nil,
+	)
+
+	meshDescs = []*prometheus.Desc{
+		meshNodeInfo,
+		meshLinkConnected,
+		meshLinkDataRate,
+		meshLinkMaxDataRate,
+		meshInterfaceConnected,
+		meshInterfaceDataRate,
+		meshInterfaceMaxDataRate,
+	}
+)
+
+// meshGraph is the mesh topology as served by the /mesh endpoint.
+// Nodes without links which are not part of the mesh are left out.
+type meshGraph struct {
+	Nodes []meshGraphNode `json:"nodes"`
+	Links []meshGraphLink `json:"links"`
+}
+
+type meshGraphNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Model    string `json:"model,omitempty"`
+	MAC      string `json:"mac,omitempty"`
+	Role     string `json:"role"`
+	Firmware string `json:"firmware_version,omitempty"`
+	Meshed   bool   `json:"meshed"`
+
+	Interfaces []meshGraphInterface `json:"interfaces,omitempty"`
+}
+
+// meshGraphInterface is an interface of a node with the sum of its links
+type meshGraphInterface struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	MAC       string `json:"mac,omitempty"`
+	Connected bool   `json:"connected"`
+
+	// Bit/s as seen from the interface
+	DataRateRX    float64 `json:"data_rate_rx"`
+	DataRateTX    float64 `json:"data_rate_tx"`
+	MaxDataRateRX float64 `json:"max_data_rate_rx"`
+	MaxDataRateTX float64 `json:"max_data_rate_tx"`
+}
+
+type meshGraphLink struct {
+	ID         string `json:"id"`
+	Node1      string `json:"node_1"`
+	Interface1 string `json:"interface_1"`
+	Node2      string `json:"node_2"`
+	Interface2 string `json:"interface_2"`
+	Type       string `json:"type"`
+	Connected  bool   `json:"connected"`
+
+	// Bit/s as seen from node 1
+	DataRateRX    float64 `json:"data_rate_rx"`
+	DataRateTX    float64 `json:"data_rate_tx"`
+	MaxDataRateRX float64 `json:"max_data_rate_rx"`
+	MaxDataRateTX float64 `json:"max_data_rate_tx"`
+}
+
+// newMeshGraph builds the graph of the mesh. Every link is contained once,
+// although the mesh list has it at the interfaces of both nodes.
+func newMeshGraph(mesh *hosts.Mesh) *meshGraph {
+	interfaces := make(map[string]string)
+	for _, n := range mesh.Nodes {
+		for _, i := range n.Interfaces {
+			interfaces[i.UID] = i.Name
+		}
+	}
+
+	g := &meshGraph{}
+	linked := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, n := range mesh.Nodes {
+		for _, i := range n.Interfaces {
+			for _, l := range i.Links {
+				if seen[l.UID] {
+					continue
+				}
+				seen[l.UID] = true
+				linked[l.Node1UID], linked[l.Node2UID] = true, true
+
+				g.Links = append(g.Links, meshGraphLink{
+					ID:            l.UID,
+					Node1:         l.Node1UID,
+					Interface1:    interfaces[l.Interface1ID],
+					Node2:         l.Node2UID,
+					Interface2:    interfaces[l.Interface2ID],
+					Type:          l.Type,
+					Connected:     l.Connected(),
+					DataRateRX:    float64(l.CurDataRateRX) * 1000,
+					DataRateTX:    float64(l.CurDataRateTX) * 1000,
+					MaxDataRateRX: float64(l.MaxDataRateRX) * 1000,
+					MaxDataRateTX: float64(l.MaxDataRateTX) * 1000,
+				})
+			}
+		}
+	}
+
+	for _, n := range mesh.Nodes {
+		if !n.IsMeshed && !linked[n.UID] {
+			continue
+		}
+		node := meshGraphNode{
+			ID:       n.UID,
+			Name:     n.DeviceName,
+			Model:    n.DeviceModel,
+			MAC:      n.MACAddress,
+			Role:     n.MeshRole,
+			Firmware: n.FirmwareVersion,
+			Meshed:   n.IsMeshed,
+		}
+		for _, i := range n.Interfaces {
+			node.Interfaces = append(node.Interfaces, newMeshGraphInterface(i))
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	sort.Slice(g.Links, func(i, j int) bool { return g.Links[i].ID < g.Links[j].ID })
+	return g
+}
+
+// newMeshGraphInterface sums up the links of the interface, each link once
+func newMeshGraphInterface(i hosts.MeshInterface) meshGraphInterface {
+	gi := meshGraphInterface{ID: i.UID, Name: i.Name, Type: i.Type, MAC: i.MACAddress}
+	seen := make(map[string]bool)
+	for _, l := range i.Links {
+		if seen[l.UID] {
+			continue
+		}
+		seen[l.UID] = true
+
+		gi.Connected = gi.Connected || l.Connected()
+		rx, tx, maxRX, maxTX := l.CurDataRateRX, l.CurDataRateTX, l.MaxDataRateRX, l.MaxDataRateTX
+		if l.Interface1ID != i.UID {
+			// The rates are seen from node 1
+			rx, tx, maxRX, maxTX = tx, rx, maxTX, maxRX
+		}
+		gi.DataRateRX += float64(rx) * 1000
+		gi.DataRateTX += float64(tx) * 1000
+		gi.MaxDataRateRX += float64(maxRX) * 1000
+		gi.MaxDataRateTX += float64(maxTX) * 1000
+	}
+	return gi
+}
+
+// node returns the node with the ID
+func (g *meshGraph) node(id string) meshGraphNode {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return meshGraphNode{ID: id, Name: id}
+}
+
+// writeDOT writes the graph in the Graphviz DOT language. Mesh nodes are boxes,
+// disconnected links are dashed.
+func (g *meshGraph) writeDOT(w io.Writer) {
+	fmt.Fprintln(w, "graph mesh {")
+	for _, n := range g.Nodes {
+		shape := "ellipse"
+		if n.Meshed {
+			shape = "box"
+		}
+		label := n.Name
+		if n.Model != "" {
+			label += "\n" + n.Model
+		}
+		fmt.Fprintf(w, "\t%q [label=%q, shape=%s];\n", n.ID, label, shape)
+	}
+	for _, l := range g.Links {
+		style := "solid"
+		if !l.Connected {
+			style = "dashed"
+		}
+		label := fmt.Sprintf("%s\n%.0f/%.0f Mbit/s", l.Type, l.DataRateRX/1e6, l.DataRateTX/1e6)
+		fmt.Fprintf(w, "\t%q -- %q [label=%q, style=%s];\n", l.Node1, l.Node2, label, style)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// meshGraph downloads the mesh topology
+func (fc *FritzboxCollector) meshGraph(ctx context.Context, root *fritzboxmetrics.Root) (*meshGraph, error) {
+	client, err := hosts.New(root, fritzboxmetrics.SourceTR64)
+	if err != nil {
+		return nil, err
+	}
+	mesh, err := client.MeshList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newMeshGraph(mesh), nil
+}
+
+// collectMesh exports the mesh nodes with their interfaces and the links
+// between them, the backhaul. Links to other hosts are only part of the /mesh
+// topology and of the interface sums.
+func (fc *FritzboxCollector) collectMesh(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	if !fc.Mesh {
+		return
+	}
+
+	g, err := fc.meshGraph(ctx, root)
+	if err != nil {
+		reportCallError(hosts.ServiceType, "X_AVM-DE_GetMeshListPath", err)
+		return
+	}
+
+	// A node or link listed twice, or nodes without MAC address and with the
+	// same name, would give duplicate series and fail the scrape
+	seen := make(map[string]bool)
+	unique := func(desc *prometheus.Desc, labels []string) bool {
+		key := desc.String() + "\x00" + strings.Join(labels, "\x00")
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+
+	for _, n := range g.Nodes {
+		if !n.Meshed {
+			continue
+		}
+		if labels := []string{fc.Gateway, n.Name, n.Model, n.MAC, n.Role, n.Firmware}; unique(meshNodeInfo, labels) {
+			ch <- prometheus.MustNewConstMetric(meshNodeInfo, prometheus.GaugeValue, 1, labels...)
+		}
+
+		for _, i := range n.Interfaces {
+			labels := []string{fc.Gateway, n.Name, n.MAC, i.Name, i.Type}
+			if !unique(meshInterfaceConnected, labels) {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(meshInterfaceConnected, prometheus.GaugeValue, boolToFloat(i.Connected), labels...)
+			ch <- prometheus.MustNewConstMetric(meshInterfaceDataRate, prometheus.GaugeValue, i.DataRateRX, append(labels, "rx")...)
+			ch <- prometheus.MustNewConstMetric(meshInterfaceDataRate, prometheus.GaugeValue, i.DataRateTX, append(labels, "tx")...)
+			ch <- prometheus.MustNewConstMetric(meshInterfaceMaxDataRate, prometheus.GaugeValue, i.MaxDataRateRX, append(labels, "rx")...)
+			ch <- prometheus.MustNewConstMetric(meshInterfaceMaxDataRate, prometheus.GaugeValue, i.MaxDataRateTX, append(labels, "tx")...)
+		}
+	}
+
+	for _, l := range g.Links {
+		node1, node2 := g.node(l.Node1), g.node(l.Node2)
+		if !node1.Meshed || !node2.Meshed {
+			continue
+		}
+
+		labels := []string{fc.Gateway, node1.Name, node1.MAC, l.Interface1, node2.Name, node2.MAC, l.Interface2, l.Type}
+		if !unique(meshLinkConnected, labels) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(meshLinkConnected, prometheus.GaugeValue, boolToFloat(l.Connected), labels...)
+		ch <- prometheus.MustNewConstMetric(meshLinkDataRate, prometheus.GaugeValue, l.DataRateRX, append(labels, "rx")...)
+		ch <- prometheus.MustNewConstMetric(meshLinkDataRate, prometheus.GaugeValue, l.DataRateTX, append(labels, "tx")...)
+		ch <- prometheus.MustNewConstMetric(meshLinkMaxDataRate, prometheus.GaugeValue, l.MaxDataRateRX, append(labels, "rx")...)
+		ch <- prometheus.MustNewConstMetric(meshLinkMaxDataRate, prometheus.GaugeValue, l.MaxDataRateTX, append(labels, "tx")...)
+	}
+}
+
+// serveMesh serves the mesh topology as JSON, or as Graphviz DOT with ?format=dot
+func (fc *FritzboxCollector) serveMesh(w http.ResponseWriter, r *http.Request) {
+	fc.Lock()
+	root := fc.Root
+	fc.Unlock()
+
+	if root == nil {
+		http.Error(w, "services not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), fc.Timeout)
+	defer cancel()
+
+	g, err := fc.meshGraph(ctx, root)
+	if err != nil {
+		log.Printf("could not get mesh topology: %v", err)
+		http.Error(w, "could not get mesh topology", http.StatusBadGateway)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(g); err != nil {
+			log.Printf("could not write mesh topology: %v", err)
+		}
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		g.writeDOT(w)
+	default:
+		http.Error(w, "unknown format, use json or dot", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/hosts"
+)
+
+var meshMetricNames = []string{
+	"fritzbox_mesh_interface_connected",
+	"fritzbox_mesh_interface_data_rate_bits_per_second",
+	"fritzbox_mesh_interface_max_data_rate_bits_per_second",
+	"fritzbox_mesh_link_connected",
+	"fritzbox_mesh_link_data_rate_bits_per_second",
+	"fritzbox_mesh_link_max_data_rate_bits_per_second",
+	"fritzbox_mesh_node_info",
+}
+
+func newMeshCollector(t *testing.T, meshList string) *FritzboxCollector {
+	s, fc := newTestCollector(t)
+	s.SetDocument("/meshlist.lua", meshList)
+	s.SetResponse(hosts.ServiceType, "X_AVM-DE_GetMeshListPath", map[string]string{
+		"NewX_AVM-DE_MeshListPath": "/meshlist.lua",
+	})
+	fc.Mesh = true
+	return fc
+}
+
+func TestCollectMesh(t *testing.T) {
+	fc := newMeshCollector(t, fritzboxtest.MeshList)
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_mesh_interface_connected Interface of a mesh node has a connected link (1) or not (0)
+# TYPE fritzbox_mesh_interface_connected gauge
+fritzbox_mesh_interface_connected{gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 1
+fritzbox_mesh_interface_connected{gateway="fritz.box",interface="LAN:1",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="LAN"} 1
+fritzbox_mesh_interface_connected{gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 1
+# HELP fritzbox_mesh_interface_data_rate_bits_per_second Current data rate of all links of the interface of a mesh node
+# TYPE fritzbox_mesh_interface_data_rate_bits_per_second gauge
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 8.66e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="LAN:1",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="LAN"} 0
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 9.75e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 9.75e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="LAN:1",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="LAN"} 0
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 8.66e+08
+# HELP fritzbox_mesh_interface_max_data_rate_bits_per_second Maximum data rate of all links of the interface of a mesh node
+# TYPE fritzbox_mesh_interface_max_data_rate_bits_per_second gauge
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 1.3e+09
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="LAN:1",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="LAN"} 1e+09
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 1.3e+09
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 1.3e+09
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="LAN:1",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="LAN"} 1e+09
+fritzbox_mesh_interface_max_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 1.3e+09
+# HELP fritzbox_mesh_link_connected Link between two mesh nodes is connected (1) or not (0)
+# TYPE fritzbox_mesh_link_connected gauge
+fritzbox_mesh_link_connected{gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 1
+# HELP fritzbox_mesh_link_data_rate_bits_per_second Current data rate of the link, direction as seen from node_1
+# TYPE fritzbox_mesh_link_data_rate_bits_per_second gauge
+fritzbox_mesh_link_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 8.66e+08
+fritzbox_mesh_link_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 9.75e+08
+# HELP fritzbox_mesh_link_max_data_rate_bits_per_second Maximum data rate of the link, direction as seen from node_1
+# TYPE fritzbox_mesh_link_max_data_rate_bits_per_second gauge
+fritzbox_mesh_link_max_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 1.3e+09
+fritzbox_mesh_link_max_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 1.3e+09
+# HELP fritzbox_mesh_node_info Mesh node (FRITZ!Box or repeater) with its role master or slave, always 1
+# TYPE fritzbox_mesh_node_info gauge
+fritzbox_mesh_node_info{firmware_version="154.07.29",gateway="fritz.box",mac="02:00:00:00:00:10",model="FRITZ!Box 7590",node="fritz.box",role="master"} 1
+fritzbox_mesh_node_info{firmware_version="181.07.29",gateway="fritz.box",mac="02:00:00:00:00:20",model="FRITZ!Repeater 3000",node="fritz.repeater",role="slave"} 1
+`, meshMetricNames...)
+}
+
+func TestServeMesh(t *testing.T) {
+	fc := newMeshCollector(t, fritzboxtest.MeshList)
+
+	rec := httptest.NewRecorder()
+	fc.serveMesh(rec, httptest.NewRequest(http.MethodGet, "/mesh", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var g meshGraph
+	if err := json.Unmarshal(rec.Body.Bytes(), &g); err != nil {
+		t.Fatalf("could not decode the topology: %v", err)
+	}
+	// The host behind the repeater is part of the topology, the unlinked host isn't
+	if len(g.Nodes) != 3 || len(g.Links) != 2 {
+		t.Errorf("got %d nodes and %d links, want 3 and 2", len(g.Nodes), len(g.Links))
+	}
+
+	rec = httptest.NewRecorder()
+	fc.serveMesh(rec, httptest.NewRequest(http.MethodGet, "/mesh?format=dot", nil))
+	if dot := rec.Body.String(); !strings.HasPrefix(dot, "graph mesh {") || !strings.Contains(dot, `"n-2" -- "n-3"`) {
+		t.Errorf("unexpected DOT output:\n%s", dot)
+	}
+
+	rec = httptest.NewRecorder()
+	fc.serveMesh(rec, httptest.NewRequest(http.MethodGet, "/mesh?format=svg", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an unknown format, want 400", rec.Code)
+	}
+}
+
+// Repeaters of the same model keep their default name
+const meshListSameNames = `{
+"schema_version": "5.4",
+"nodes": [
+{"uid": "n-1", "device_name": "fritz.box", "device_model": "FRITZ!Box 7590", "device_mac_address": "02:00:00:00:00:10", "is_meshed": true, "mesh_role": "master",
+ "node_interfaces": [
+  {"uid": "ni-11", "name": "AP:5G:0", "type": "WLAN", "mac_address": "02:00:00:00:00:11",
+   "node_links": [
+    {"uid": "nl-20", "type": "WLAN", "state": "CONNECTED", "node_1_uid": "n-1", "node_2_uid": "n-2", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-21", "cur_data_rate_rx": 866000, "cur_data_rate_tx": 975000},
+    {"uid": "nl-30", "type": "WLAN", "state": "CONNECTED", "node_1_uid": "n-1", "node_2_uid": "n-3", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-31", "cur_data_rate_rx": 400000, "cur_data_rate_tx": 300000}]}
+ ]},
+{"uid": "n-2", "device_name": "fritz.repeater", "device_model": "FRITZ!Repeater 3000", "device_mac_address": "02:00:00:00:00:20", "is_meshed": true, "mesh_role": "slave",
+ "node_interfaces": [
+  {"uid": "ni-21", "name": "UPLINK:5G:0", "type": "WLAN", "mac_address": "02:00:00:00:00:21",
+   "node_links": [{"uid": "nl-20", "type": "WLAN", "state": "CONNECTED", "node_1_uid": "n-1", "node_2_uid": "n-2", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-21", "cur_data_rate_rx": 866000, "cur_data_rate_tx": 975000}]}
+ ]},
+{"uid": "n-3", "device_name": "fritz.repeater", "device_model": "FRITZ!Repeater 3000", "device_mac_address": "02:00:00:00:00:30", "is_meshed": true, "mesh_role": "slave",
+ "node_interfaces": [
+  {"uid": "ni-31", "name": "UPLINK:5G:0", "type": "WLAN", "mac_address": "02:00:00:00:00:31",
+   "node_links": [{"uid": "nl-30", "type": "WLAN", "state": "CONNECTED", "node_1_uid": "n-1", "node_2_uid": "n-3", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-31", "cur_data_rate_rx": 400000, "cur_data_rate_tx": 300000}]}
+ ]}
+]
+}
+`
+
+func TestCollectMeshSameNames(t *testing.T) {
+	fc := newMeshCollector(t, meshListSameNames)
+
+	assertScrape(t, fc)
+	assertMetrics(t, fc, `
+# HELP fritzbox_mesh_interface_data_rate_bits_per_second Current data rate of all links of the interface of a mesh node
+# TYPE fritzbox_mesh_interface_data_rate_bits_per_second gauge
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 1.266e+09
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 9.75e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="rx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:30",type="WLAN"} 3e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="AP:5G:0",node="fritz.box",node_mac="02:00:00:00:00:10",type="WLAN"} 1.275e+09
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:20",type="WLAN"} 8.66e+08
+fritzbox_mesh_interface_data_rate_bits_per_second{direction="tx",gateway="fritz.box",interface="UPLINK:5G:0",node="fritz.repeater",node_mac="02:00:00:00:00:30",type="WLAN"} 4e+08
+# HELP fritzbox_mesh_link_connected Link between two mesh nodes is connected (1) or not (0)
+# TYPE fritzbox_mesh_link_connected gauge
+fritzbox_mesh_link_connected{gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:20",type="WLAN"} 1
+fritzbox_mesh_link_connected{gateway="fritz.box",interface_1="AP:5G:0",interface_2="UPLINK:5G:0",node_1="fritz.box",node_1_mac="02:00:00:00:00:10",node_2="fritz.repeater",node_2_mac="02:00:00:00:00:30",type="WLAN"} 1
+`, "fritzbox_mesh_interface_data_rate_bits_per_second", "fritzbox_mesh_link_connected")
+}
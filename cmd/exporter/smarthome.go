@@ -0,0 +1,378 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/homeauto"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sources of the smart home metrics
+const (
+	smartHomeAHA   = "aha"
+	smartHomeTR064 = "tr064"
+)
+
+var smartHomeLabels = []string{"gateway", "ain", "name", "productname"}
+
+var (
+	smartHomePresent = prometheus.NewDesc(
+		"fritzbox_smarthome_present",
+		"Smart home device is connected (1) or not (0)",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeSwitchOn = prometheus.NewDesc(
+		"fritzbox_smarthome_switch_on",
+		"Switch of the smart home device is on (1) or off (0)",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomePower = prometheus.NewDesc(
+		"fritzbox_smarthome_power_watts",
+		"Current power consumption of the smart home device",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeEnergy = prometheus.NewDesc(
+		"fritzbox_smarthome_energy_watt_hours_total",
+		"Energy consumed by the smart home device",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeVoltage = prometheus.NewDesc(
+		"fritzbox_smarthome_voltage_volts",
+		"Voltage at the smart home device",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeTemperature = prometheus.NewDesc(
+		"fritzbox_smarthome_temperature_celsius",
+		"Temperature measured by the smart home device",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeThermostatTarget = prometheus.NewDesc(
+		"fritzbox_smarthome_thermostat_target_celsius",
+		"Target temperature of the thermostat",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeThermostatCurrent = prometheus.NewDesc(
+		"fritzbox_smarthome_thermostat_current_celsius",
+		"Current temperature measured by the thermostat",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeBattery = prometheus.NewDesc(
+		"fritzbox_smarthome_battery_percent",
+		"Battery level of the smart home device",
+		smartHomeLabels,
+		nil,
+	)
+	smartHomeWindowOpen = prometheus.NewDesc(
+		"fritzbox_smarthome_window_open",
+		"Thermostat detected an open window (1) or not (0)",
+		smartHomeLabels,
+		nil,
+	)
+
+	smartHomeDescs = []*prometheus.Desc{
+		smartHomePresent,
+		smartHomeSwitchOn,
+		smartHomePower,
+		smartHomeEnergy,
+		smartHomeVoltage,
+		smartHomeTemperature,
+		smartHomeThermostatTarget,
+		smartHomeThermostatCurrent,
+		smartHomeBattery,
+		smartHomeWindowOpen,
+	}
+)
+
+// smartHomeDevice are the values of a smart home device independent of
+// the interface they were read from. Values the device doesn't support are nil.
+type smartHomeDevice struct {
+	AIN         string
+	Name        string
+	ProductName string
+	Present     bool
+
+	SwitchOn           *bool
+	PowerWatts         *float64
+	EnergyWattHours    *float64
+	VoltageVolts       *float64
+	TemperatureCelsius *float64
+	TargetCelsius      *float64
+	CurrentCelsius     *float64
+	BatteryPercent     *float64
+	WindowOpen         *bool
+}
+
+// collectSmartHome exports the smart home devices from the configured source
+func (fc *FritzboxCollector) collectSmartHome(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	var devices []smartHomeDevice
+	var err error
+
+	switch fc.SmartHome {
+	case "":
+		return
+	case smartHomeAHA:
+		devices, err = fc.ahaDevices(ctx)
+	case smartHomeTR064:
+		devices, err = fc.tr064SmartHomeDevices(ctx, root)
+	}
+	if err != nil {
+		log.Printf("could not get smart home devices: %v", err)
+		collectErrors.Inc()
+		return
+	}
+
+	for _, d := range devices {
+		fc.collectSmartHomeDevice(ch, d)
+	}
+}
+
+func (fc *FritzboxCollector) collectSmartHomeDevice(ch chan<- prometheus.Metric, d smartHomeDevice) {
+	labels := []string{fc.Gateway, d.AIN, d.Name, d.ProductName}
+	gauge := func(desc *prometheus.Desc, val float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, val, labels...)
+	}
+
+	gauge(smartHomePresent, boolToFloat(d.Present))
+	if !d.Present {
+		// A device which isn't connected reports empty values
+		return
+	}
+	if d.SwitchOn != nil {
+		gauge(smartHomeSwitchOn, boolToFloat(*d.SwitchOn))
+	}
+	if d.PowerWatts != nil {
+		gauge(smartHomePower, *d.PowerWatts)
+	}
+	if d.EnergyWattHours != nil {
+		ch <- prometheus.MustNewConstMetric(smartHomeEnergy, prometheus.CounterValue, *d.EnergyWattHours, labels...)
+	}
+	if d.VoltageVolts != nil {
+		gauge(smartHomeVoltage, *d.VoltageVolts)
+	}
+	if d.TemperatureCelsius != nil {
+		gauge(smartHomeTemperature, *d.TemperatureCelsius)
+	}
+	if d.TargetCelsius != nil {
+		gauge(smartHomeThermostatTarget, *d.TargetCelsius)
+	}
+	if d.CurrentCelsius != nil {
+		gauge(smartHomeThermostatCurrent, *d.CurrentCelsius)
+	}
+	if d.BatteryPercent != nil {
+		gauge(smartHomeBattery, *d.BatteryPercent)
+	}
+	if d.WindowOpen != nil {
+		gauge(smartHomeWindowOpen, boolToFloat(*d.WindowOpen))
+	}
+}
+
+// ahaDevices reads the smart home devices from the AHA HTTP interface
+func (fc *FritzboxCollector) ahaDevices(ctx context.Context) ([]smartHomeDevice, error) {
+	ahaDevices, err := fc.Session.AHADevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]smartHomeDevice, 0, len(ahaDevices))
+	for _, a := range ahaDevices {
+		d := smartHomeDevice{
+			AIN:         a.AIN,
+			Name:        a.Name,
+			ProductName: a.ProductName,
+			Present:     a.Present,
+		}
+
+		if on, ok := a.SwitchOn(); ok {
+			d.SwitchOn = &on
+		}
+		if a.PowerMeter != nil {
+			d.PowerWatts = float64Ptr(float64(a.PowerMeter.Power) / 1000)
+			d.EnergyWattHours = float64Ptr(float64(a.PowerMeter.Energy))
+			d.VoltageVolts = float64Ptr(float64(a.PowerMeter.Voltage) / 1000)
+		}
+		if a.Temperature != nil {
+			d.TemperatureCelsius = float64Ptr(float64(a.Temperature.Celsius) / 10)
+		}
+		if a.Thermostat != nil {
+			if c, ok := fritzboxmetrics.ThermostatCelsius(a.Thermostat.Target); ok {
+				d.TargetCelsius = &c
+			}
+			if c, ok := fritzboxmetrics.ThermostatCelsius(a.Thermostat.Current); ok {
+				d.CurrentCelsius = &c
+			}
+			windowOpen := a.Thermostat.WindowOpen
+			d.WindowOpen = &windowOpen
+		}
+		if battery, ok := a.BatteryPercent(); ok {
+			d.BatteryPercent = float64Ptr(float64(battery))
+		}
+
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// homeautoInfo are the values of GetGenericDeviceInfos and GetSpecificDeviceInfos used for the metrics
+type homeautoInfo struct {
+	AIN, DeviceName, ProductName, Present string
+
+	MultimeterIsEnabled, MultimeterIsValid string
+	MultimeterPower, MultimeterEnergy      uint64 // 0.01 W, Wh
+
+	TemperatureIsEnabled, TemperatureIsValid string
+	TemperatureCelsius                       int64 // 0.1 °C
+
+	SwitchIsEnabled, SwitchIsValid, SwitchState string
+
+	HkrIsEnabled, HkrIsValid, HkrSetVentilStatus string
+	HkrIsTemperature, HkrSetTemperature          uint64 // 0.1 °C
+}
+
+// tr064SmartHomeDevices reads the smart home devices through the X_AVM-DE_Homeauto service.
+// If AINs are configured only these devices are looked up, otherwise all devices are listed.
+func (fc *FritzboxCollector) tr064SmartHomeDevices(ctx context.Context, root *fritzboxmetrics.Root) ([]smartHomeDevice, error) {
+	client, err := homeauto.New(root, fritzboxmetrics.SourceTR64)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []smartHomeDevice
+	if len(fc.SmartHomeAINs) > 0 {
+		for _, ain := range fc.SmartHomeAINs {
+			r, err := client.GetSpecificDeviceInfos(ctx, ain)
+			if err != nil {
+				return nil, err
+			}
+			devices = append(devices, homeautoFromSpecific(ain, r).device())
+		}
+		return devices, nil
+	}
+
+	for index := 0; index <= math.MaxUint16; index++ {
+		r, err := client.GetGenericDeviceInfos(ctx, uint16(index))
+		if errors.Is(err, fritzboxmetrics.ErrSpecifiedArrayIndexInvalid) || errors.Is(err, fritzboxmetrics.ErrNoSuchEntryInArray) {
+			// End of the device list
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, homeautoFromGeneric(r).device())
+	}
+	return devices, nil
+}
+
+func homeautoFromGeneric(r homeauto.GetGenericDeviceInfosResponse) homeautoInfo {
+	return homeautoInfo{
+		AIN:                  r.AIN,
+		DeviceName:           r.DeviceName,
+		ProductName:          r.ProductName,
+		Present:              r.Present,
+		MultimeterIsEnabled:  r.MultimeterIsEnabled,
+		MultimeterIsValid:    r.MultimeterIsValid,
+		MultimeterPower:      r.MultimeterPower,
+		MultimeterEnergy:     r.MultimeterEnergy,
+		TemperatureIsEnabled: r.TemperatureIsEnabled,
+		TemperatureIsValid:   r.TemperatureIsValid,
+		TemperatureCelsius:   r.TemperatureCelsius,
+		SwitchIsEnabled:      r.SwitchIsEnabled,
+		SwitchIsValid:        r.SwitchIsValid,
+		SwitchState:          r.SwitchState,
+		HkrIsEnabled:         r.HkrIsEnabled,
+		HkrIsValid:           r.HkrIsValid,
+		HkrSetVentilStatus:   r.HkrSetVentilStatus,
+		HkrIsTemperature:     r.HkrIsTemperature,
+		HkrSetTemperature:    r.HkrSetTemperature,
+	}
+}
+
+func homeautoFromSpecific(ain string, r homeauto.GetSpecificDeviceInfosResponse) homeautoInfo {
+	return homeautoInfo{
+		AIN:                  ain,
+		DeviceName:           r.DeviceName,
+		ProductName:          r.ProductName,
+		Present:              r.Present,
+		MultimeterIsEnabled:  r.MultimeterIsEnabled,
+		MultimeterIsValid:    r.MultimeterIsValid,
+		MultimeterPower:      r.MultimeterPower,
+		MultimeterEnergy:     r.MultimeterEnergy,
+		TemperatureIsEnabled: r.TemperatureIsEnabled,
+		TemperatureIsValid:   r.TemperatureIsValid,
+		TemperatureCelsius:   r.TemperatureCelsius,
+		SwitchIsEnabled:      r.SwitchIsEnabled,
+		SwitchIsValid:        r.SwitchIsValid,
+		SwitchState:          r.SwitchState,
+		HkrIsEnabled:         r.HkrIsEnabled,
+		HkrIsValid:           r.HkrIsValid,
+		HkrSetVentilStatus:   r.HkrSetVentilStatus,
+		HkrIsTemperature:     r.HkrIsTemperature,
+		HkrSetTemperature:    r.HkrSetTemperature,
+	}
+}
+
+// device converts the values of the TR-064 interface, only valid values are kept
+func (h homeautoInfo) device() smartHomeDevice {
+	d := smartHomeDevice{
+		AIN:         h.AIN,
+		Name:        h.DeviceName,
+		ProductName: h.ProductName,
+		Present:     h.Present == "CONNECTED",
+	}
+
+	if h.SwitchIsEnabled == "ENABLED" && h.SwitchIsValid == "VALID" && (h.SwitchState == "ON" || h.SwitchState == "OFF") {
+		on := h.SwitchState == "ON"
+		d.SwitchOn = &on
+	}
+	if h.MultimeterIsEnabled == "ENABLED" && h.MultimeterIsValid == "VALID" {
+		d.PowerWatts = float64Ptr(float64(h.MultimeterPower) / 100)
+		d.EnergyWattHours = float64Ptr(float64(h.MultimeterEnergy))
+	}
+	if h.TemperatureIsEnabled == "ENABLED" && h.TemperatureIsValid == "VALID" {
+		d.TemperatureCelsius = float64Ptr(float64(h.TemperatureCelsius) / 10)
+	}
+	if h.HkrIsEnabled == "ENABLED" && h.HkrIsValid == "VALID" {
+		d.CurrentCelsius = float64Ptr(float64(h.HkrIsTemperature) / 10)
+		// OPEN and CLOSED mean the thermostat is switched on or off
+		if h.HkrSetVentilStatus == "TEMP" {
+			d.TargetCelsius = float64Ptr(float64(h.HkrSetTemperature) / 10)
+		}
+	}
+	return d
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
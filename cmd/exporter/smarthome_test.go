@@ -0,0 +1,243 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/homeauto"
+)
+
+func TestCollectSmartHomeAHA(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetAHAResponse("getdevicelistinfos", fritzboxtest.AHADeviceList)
+	fc.SmartHome = smartHomeAHA
+	fc.Session = fc.Client.NewSession(s.URL)
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_smarthome_battery_percent Battery level of the smart home device
+# TYPE fritzbox_smarthome_battery_percent gauge
+fritzbox_smarthome_battery_percent{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 80
+# HELP fritzbox_smarthome_energy_watt_hours_total Energy consumed by the smart home device
+# TYPE fritzbox_smarthome_energy_watt_hours_total counter
+fritzbox_smarthome_energy_watt_hours_total{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 70710
+# HELP fritzbox_smarthome_power_watts Current power consumption of the smart home device
+# TYPE fritzbox_smarthome_power_watts gauge
+fritzbox_smarthome_power_watts{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1243
+# HELP fritzbox_smarthome_present Smart home device is connected (1) or not (0)
+# TYPE fritzbox_smarthome_present gauge
+fritzbox_smarthome_present{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1
+fritzbox_smarthome_present{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 1
+# HELP fritzbox_smarthome_switch_on Switch of the smart home device is on (1) or off (0)
+# TYPE fritzbox_smarthome_switch_on gauge
+fritzbox_smarthome_switch_on{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1
+# HELP fritzbox_smarthome_temperature_celsius Temperature measured by the smart home device
+# TYPE fritzbox_smarthome_temperature_celsius gauge
+fritzbox_smarthome_temperature_celsius{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 22.5
+fritzbox_smarthome_temperature_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 20.5
+# HELP fritzbox_smarthome_thermostat_current_celsius Current temperature measured by the thermostat
+# TYPE fritzbox_smarthome_thermostat_current_celsius gauge
+fritzbox_smarthome_thermostat_current_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 20.5
+# HELP fritzbox_smarthome_thermostat_target_celsius Target temperature of the thermostat
+# TYPE fritzbox_smarthome_thermostat_target_celsius gauge
+fritzbox_smarthome_thermostat_target_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 22
+# HELP fritzbox_smarthome_voltage_volts Voltage at the smart home device
+# TYPE fritzbox_smarthome_voltage_volts gauge
+fritzbox_smarthome_voltage_volts{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 230.051
+# HELP fritzbox_smarthome_window_open Thermostat detected an open window (1) or not (0)
+# TYPE fritzbox_smarthome_window_open gauge
+fritzbox_smarthome_window_open{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 1
+`, smartHomeMetricNames...)
+}
+
+func TestCollectSmartHomeAHANotPresent(t *testing.T) {
+	s, fc := newTestCollector(t)
+	s.SetAHAResponse("getdevicelistinfos", `<devicelist version="1" fwversion="7.29">
+<device identifier="08761 0000434" id="17" functionbitmask="35712" fwversion="04.25" manufacturer="AVM" productname="FRITZ!DECT 200">
+<present>0</present>
+<txbusy>0</txbusy>
+<name>Kaffeemaschine</name>
+<switch><state></state><mode></mode><lock></lock><devicelock></devicelock></switch>
+<simpleonoff><state></state></simpleonoff>
+<powermeter><voltage></voltage><power></power><energy></energy></powermeter>
+<temperature><celsius></celsius><offset></offset></temperature>
+</device>
+</devicelist>`)
+	fc.SmartHome = smartHomeAHA
+	fc.Session = fc.Client.NewSession(s.URL)
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_smarthome_present Smart home device is connected (1) or not (0)
+# TYPE fritzbox_smarthome_present gauge
+fritzbox_smarthome_present{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 0
+`, smartHomeMetricNames...)
+}
+
+var smartHomeMetricNames = []string{
+	"fritzbox_smarthome_battery_percent",
+	"fritzbox_smarthome_energy_watt_hours_total",
+	"fritzbox_smarthome_power_watts",
+	"fritzbox_smarthome_present",
+	"fritzbox_smarthome_switch_on",
+	"fritzbox_smarthome_temperature_celsius",
+	"fritzbox_smarthome_thermostat_current_celsius",
+	"fritzbox_smarthome_thermostat_target_celsius",
+	"fritzbox_smarthome_voltage_volts",
+	"fritzbox_smarthome_window_open",
+}
+
+// homeautoDevices are the answers of the X_AVM-DE_Homeauto actions for a
+// FRITZ!DECT 200 plug and a FRITZ!DECT 301 thermostat, indexed by AIN
+var homeautoDevices = []map[string]string{
+	{
+		"NewAIN":                    "08761 0000434",
+		"NewDeviceId":               "16",
+		"NewFunctionBitMask":        "2944",
+		"NewFirmwareVersion":        "04.25",
+		"NewManufacturer":           "AVM",
+		"NewDeviceName":             "Kaffeemaschine",
+		"NewProductName":            "FRITZ!DECT 200",
+		"NewPresent":                "CONNECTED",
+		"NewMultimeterIsEnabled":    "ENABLED",
+		"NewMultimeterIsValid":      "VALID",
+		"NewMultimeterPower":        "124300",
+		"NewMultimeterEnergy":       "70710",
+		"NewTemperatureIsEnabled":   "ENABLED",
+		"NewTemperatureIsValid":     "VALID",
+		"NewTemperatureCelsius":     "225",
+		"NewTemperatureOffset":      "0",
+		"NewSwitchIsEnabled":        "ENABLED",
+		"NewSwitchIsValid":          "VALID",
+		"NewSwitchState":            "ON",
+		"NewSwitchMode":             "MANUAL",
+		"NewSwitchLock":             "0",
+		"NewHkrIsEnabled":           "DISABLED",
+		"NewHkrIsValid":             "INVALID",
+		"NewHkrIsTemperature":       "0",
+		"NewHkrSetVentilStatus":     "CLOSED",
+		"NewHkrSetTemperature":      "0",
+		"NewHkrReduceVentilStatus":  "CLOSED",
+		"NewHkrReduceTemperature":   "0",
+		"NewHkrComfortVentilStatus": "CLOSED",
+		"NewHkrComfortTemperature":  "0",
+	},
+	{
+		"NewAIN":                    "09995 0123456",
+		"NewDeviceId":               "17",
+		"NewFunctionBitMask":        "320",
+		"NewFirmwareVersion":        "05.08",
+		"NewManufacturer":           "AVM",
+		"NewDeviceName":             "Büro",
+		"NewProductName":            "FRITZ!DECT 301",
+		"NewPresent":                "CONNECTED",
+		"NewMultimeterIsEnabled":    "DISABLED",
+		"NewMultimeterIsValid":      "INVALID",
+		"NewMultimeterPower":        "0",
+		"NewMultimeterEnergy":       "0",
+		"NewTemperatureIsEnabled":   "ENABLED",
+		"NewTemperatureIsValid":     "VALID",
+		"NewTemperatureCelsius":     "205",
+		"NewTemperatureOffset":      "0",
+		"NewSwitchIsEnabled":        "DISABLED",
+		"NewSwitchIsValid":          "INVALID",
+		"NewSwitchState":            "UNDEFINED",
+		"NewSwitchMode":             "UNDEFINED",
+		"NewSwitchLock":             "0",
+		"NewHkrIsEnabled":           "ENABLED",
+		"NewHkrIsValid":             "VALID",
+		"NewHkrIsTemperature":       "205",
+		"NewHkrSetVentilStatus":     "TEMP",
+		"NewHkrSetTemperature":      "220",
+		"NewHkrReduceVentilStatus":  "TEMP",
+		"NewHkrReduceTemperature":   "160",
+		"NewHkrComfortVentilStatus": "TEMP",
+		"NewHkrComfortTemperature":  "220",
+	},
+}
+
+func handleHomeauto(s *fritzboxtest.Server) {
+	s.Handle(homeauto.ServiceType, "GetGenericDeviceInfos", func(args map[string]string) (map[string]string, error) {
+		for i, d := range homeautoDevices {
+			if args["NewIndex"] == strconv.Itoa(i) {
+				return d, nil
+			}
+		}
+		return nil, &fritzboxtest.Fault{UPnPErrorCode: 713, UPnPErrorDescription: "SpecifiedArrayIndexInvalid"}
+	})
+	s.Handle(homeauto.ServiceType, "GetSpecificDeviceInfos", func(args map[string]string) (map[string]string, error) {
+		for _, d := range homeautoDevices {
+			if args["NewAIN"] == d["NewAIN"] {
+				return d, nil
+			}
+		}
+		return nil, &fritzboxtest.Fault{UPnPErrorCode: 714, UPnPErrorDescription: "NoSuchEntryInArray"}
+	})
+}
+
+const homeautoPlugMetrics = `
+# HELP fritzbox_smarthome_energy_watt_hours_total Energy consumed by the smart home device
+# TYPE fritzbox_smarthome_energy_watt_hours_total counter
+fritzbox_smarthome_energy_watt_hours_total{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 70710
+# HELP fritzbox_smarthome_power_watts Current power consumption of the smart home device
+# TYPE fritzbox_smarthome_power_watts gauge
+fritzbox_smarthome_power_watts{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1243
+# HELP fritzbox_smarthome_switch_on Switch of the smart home device is on (1) or off (0)
+# TYPE fritzbox_smarthome_switch_on gauge
+fritzbox_smarthome_switch_on{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1
+`
+
+func TestCollectSmartHomeTR064(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleHomeauto(s)
+	fc.SmartHome = smartHomeTR064
+
+	assertMetrics(t, fc, homeautoPlugMetrics+`
+# HELP fritzbox_smarthome_present Smart home device is connected (1) or not (0)
+# TYPE fritzbox_smarthome_present gauge
+fritzbox_smarthome_present{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1
+fritzbox_smarthome_present{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 1
+# HELP fritzbox_smarthome_temperature_celsius Temperature measured by the smart home device
+# TYPE fritzbox_smarthome_temperature_celsius gauge
+fritzbox_smarthome_temperature_celsius{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 22.5
+fritzbox_smarthome_temperature_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 20.5
+# HELP fritzbox_smarthome_thermostat_current_celsius Current temperature measured by the thermostat
+# TYPE fritzbox_smarthome_thermostat_current_celsius gauge
+fritzbox_smarthome_thermostat_current_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 20.5
+# HELP fritzbox_smarthome_thermostat_target_celsius Target temperature of the thermostat
+# TYPE fritzbox_smarthome_thermostat_target_celsius gauge
+fritzbox_smarthome_thermostat_target_celsius{ain="09995 0123456",gateway="fritz.box",name="Büro",productname="FRITZ!DECT 301"} 22
+`, smartHomeMetricNames...)
+}
+
+func TestCollectSmartHomeTR064AINs(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleHomeauto(s)
+	fc.SmartHome = smartHomeTR064
+	fc.SmartHomeAINs = []string{"08761 0000434"}
+
+	assertMetrics(t, fc, homeautoPlugMetrics+`
+# HELP fritzbox_smarthome_present Smart home device is connected (1) or not (0)
+# TYPE fritzbox_smarthome_present gauge
+fritzbox_smarthome_present{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 1
+# HELP fritzbox_smarthome_temperature_celsius Temperature measured by the smart home device
+# TYPE fritzbox_smarthome_temperature_celsius gauge
+fritzbox_smarthome_temperature_celsius{ain="08761 0000434",gateway="fritz.box",name="Kaffeemaschine",productname="FRITZ!DECT 200"} 22.5
+`, smartHomeMetricNames...)
+	if s.Calls(homeauto.ServiceType, "GetGenericDeviceInfos") != 0 {
+		t.Error("all devices were listed although AINs are configured")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/voip"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	voipNumbers = prometheus.NewDesc(
+		"fritzbox_voip_numbers",
+		"Number of telephone numbers configured on the FRITZ!Box",
+		[]string{"gateway"},
+		nil,
+	)
+	voipAccountRegistered = prometheus.NewDesc(
+		"fritzbox_voip_account_registered",
+		"VoIP account is registered at its SIP registrar (1) or not (0)",
+		[]string{"gateway", "account", "registrar"},
+		nil,
+	)
+	voipNumberRegistered = prometheus.NewDesc(
+		"fritzbox_voip_number_registered",
+		"Internet telephone number is registered at its SIP registrar (1) or not (0)",
+		[]string{"gateway", "account", "number", "name", "registrar"},
+		nil,
+	)
+
+	voipDescs = []*prometheus.Desc{voipNumbers, voipAccountRegistered, voipNumberRegistered}
+)
+
+// voipAccount is the registrar and registration of a VoIP account, fetched
+// once per scrape and shared by all numbers of the account
+type voipAccount struct {
+	registrar  string
+	registered bool
+}
+
+// collectVoIP exports the number of configured telephone numbers and the
+// registration of every VoIP account and of its internet telephone numbers
+func (fc *FritzboxCollector) collectVoIP(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	if !fc.VoIP {
+		return
+	}
+
+	client, err := voip.New(root, fritzboxmetrics.SourceTR64)
+	if err != nil {
+		// No telephony
+		return
+	}
+
+	count, err := client.XAVMDEGetNumberOfNumbers(ctx)
+	if err != nil {
+		reportCallError(voip.ServiceType, "X_AVM-DE_GetNumberOfNumbers", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(voipNumbers, prometheus.GaugeValue, float64(count.NumberOfNumbers), fc.Gateway)
+
+	numbers, err := client.Numbers(ctx)
+	if err != nil {
+		reportCallError(voip.ServiceType, "X_AVM-DE_GetNumbers", err)
+		return
+	}
+	seen := make(map[string]bool, len(numbers))
+	accounts := make(map[uint16]*voipAccount)
+	for _, n := range numbers {
+		if n.Type != voip.NumberTypeVoIP {
+			continue
+		}
+		// A number may be listed more than once for the same account
+		key := strconv.Itoa(int(n.Index)) + "/" + n.Number
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		account, ok := accounts[n.Index]
+		if !ok {
+			account = fc.collectVoIPAccount(ctx, ch, client, n.Index)
+			accounts[n.Index] = account
+		}
+		if account == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(voipNumberRegistered, prometheus.GaugeValue, boolToFloat(account.registered),
+			fc.Gateway, strconv.Itoa(int(n.Index)), n.Number, n.Name, account.registrar)
+	}
+}
+
+// collectVoIPAccount exports the registration of the VoIP account with the
+// given index. It returns nil if the account couldn't be read.
+func (fc *FritzboxCollector) collectVoIPAccount(ctx context.Context, ch chan<- prometheus.Metric, client *voip.Client, index uint16) *voipAccount {
+	account, err := client.XAVMDEGetVoIPAccount(ctx, index)
+	if err != nil {
+		reportCallError(voip.ServiceType, "X_AVM-DE_GetVoIPAccount", err)
+		return nil
+	}
+	status, err := client.XAVMDEGetVoIPStatus(ctx, index)
+	if err != nil {
+		reportCallError(voip.ServiceType, "X_AVM-DE_GetVoIPStatus", err)
+		return nil
+	}
+
+	a := &voipAccount{
+		registrar:  account.VoIPRegistrar,
+		registered: strings.EqualFold(status.XAVMDEVoIPStatus, "registered"),
+	}
+	ch <- prometheus.MustNewConstMetric(voipAccountRegistered, prometheus.GaugeValue, boolToFloat(a.registered),
+		fc.Gateway, strconv.Itoa(int(index)), a.registrar)
+	return a
+}
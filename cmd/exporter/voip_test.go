@@ -0,0 +1,104 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/voip"
+)
+
+var voipMetricNames = []string{"fritzbox_voip_account_registered", "fritzbox_voip_number_registered", "fritzbox_voip_numbers"}
+
+// handleVoIP answers the actions of X_VoIP with the number list and two VoIP accounts,
+// the first one registered
+func handleVoIP(s *fritzboxtest.Server, numbers string) {
+	accounts := map[string]map[string]string{
+		"0": {"NewVoIPRegistrar": "tel.t-online.de", "NewVoIPNumber": "0301234567", "NewVoIPUsername": "0301234567",
+			"NewVoIPPassword": "", "NewVoIPOutboundProxy": "", "NewVoIPSTUNServer": ""},
+		"1": {"NewVoIPRegistrar": "sipgate.de", "NewVoIPNumber": "0307654321", "NewVoIPUsername": "1234567e0",
+			"NewVoIPPassword": "", "NewVoIPOutboundProxy": "", "NewVoIPSTUNServer": "stun.sipgate.net"},
+	}
+	status := map[string]string{"0": "Registered", "1": "Connecting"}
+
+	s.SetResponse(voip.ServiceType, "X_AVM-DE_GetNumberOfNumbers", map[string]string{"NewNumberOfNumbers": "3"})
+	s.SetResponse(voip.ServiceType, "X_AVM-DE_GetNumbers", map[string]string{"NewNumberList": numbers})
+	s.Handle(voip.ServiceType, "X_AVM-DE_GetVoIPAccount", func(args map[string]string) (map[string]string, error) {
+		if a, ok := accounts[args["NewVoIPAccountIndex"]]; ok {
+			return a, nil
+		}
+		return nil, &fritzboxtest.Fault{UPnPErrorCode: 713, UPnPErrorDescription: "SpecifiedArrayIndexInvalid"}
+	})
+	s.Handle(voip.ServiceType, "X_AVM-DE_GetVoIPStatus", func(args map[string]string) (map[string]string, error) {
+		if st, ok := status[args["NewVoIPAccountIndex"]]; ok {
+			return map[string]string{"NewX_AVM-DE_VoIPStatus": st}, nil
+		}
+		return nil, &fritzboxtest.Fault{UPnPErrorCode: 713, UPnPErrorDescription: "SpecifiedArrayIndexInvalid"}
+	})
+}
+
+func TestCollectVoIP(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleVoIP(s, fritzboxtest.VoIPNumberList)
+	fc.VoIP = true
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_voip_account_registered VoIP account is registered at its SIP registrar (1) or not (0)
+# TYPE fritzbox_voip_account_registered gauge
+fritzbox_voip_account_registered{account="0",gateway="fritz.box",registrar="tel.t-online.de"} 1
+fritzbox_voip_account_registered{account="1",gateway="fritz.box",registrar="sipgate.de"} 0
+# HELP fritzbox_voip_number_registered Internet telephone number is registered at its SIP registrar (1) or not (0)
+# TYPE fritzbox_voip_number_registered gauge
+fritzbox_voip_number_registered{account="0",gateway="fritz.box",name="office",number="0301234567",registrar="tel.t-online.de"} 1
+fritzbox_voip_number_registered{account="1",gateway="fritz.box",name="fax",number="0307654321",registrar="sipgate.de"} 0
+# HELP fritzbox_voip_numbers Number of telephone numbers configured on the FRITZ!Box
+# TYPE fritzbox_voip_numbers gauge
+fritzbox_voip_numbers{gateway="fritz.box"} 3
+`, voipMetricNames...)
+}
+
+func TestCollectVoIPDuplicate(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleVoIP(s, `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<Item><Number>0301234567</Number><Type>eVoIP</Type><Index>0</Index><Name>office</Name></Item>
+<Item><Number>0301234567</Number><Type>eVoIP</Type><Index>0</Index><Name>office</Name></Item>
+<Item><Number>0301234567</Number><Type>eVoIP</Type><Index>1</Index><Name>office</Name></Item>
+<Item><Number>0309876543</Number><Type>eVoIP</Type><Index>0</Index><Name>home</Name></Item>
+</List>
+`)
+	fc.VoIP = true
+
+	assertScrape(t, fc)
+	// Every account is read once per scrape, however many numbers it has
+	for _, action := range []string{"X_AVM-DE_GetVoIPAccount", "X_AVM-DE_GetVoIPStatus"} {
+		if got := s.Calls(voip.ServiceType, action); got != 2 {
+			t.Errorf("%s called %d times, want 2", action, got)
+		}
+	}
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_voip_account_registered VoIP account is registered at its SIP registrar (1) or not (0)
+# TYPE fritzbox_voip_account_registered gauge
+fritzbox_voip_account_registered{account="0",gateway="fritz.box",registrar="tel.t-online.de"} 1
+fritzbox_voip_account_registered{account="1",gateway="fritz.box",registrar="sipgate.de"} 0
+# HELP fritzbox_voip_number_registered Internet telephone number is registered at its SIP registrar (1) or not (0)
+# TYPE fritzbox_voip_number_registered gauge
+fritzbox_voip_number_registered{account="0",gateway="fritz.box",name="home",number="0309876543",registrar="tel.t-online.de"} 1
+fritzbox_voip_number_registered{account="0",gateway="fritz.box",name="office",number="0301234567",registrar="tel.t-online.de"} 1
+fritzbox_voip_number_registered{account="1",gateway="fritz.box",name="office",number="0301234567",registrar="sipgate.de"} 0
+`, "fritzbox_voip_account_registered", "fritzbox_voip_number_registered")
+}
@@ -0,0 +1,155 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/services/wlanconfiguration"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const wlanDeviceListPathAction = "X_AVM-DE_GetWLANDeviceListPath"
+
+var (
+	wlanLabels       = []string{"gateway", "wlan"}
+	wlanClientLabels = []string{"gateway", "wlan", "mac", "ip"}
+)
+
+var (
+	wlanInfo = prometheus.NewDesc(
+		"fritzbox_wlan_info",
+		"Information about the WLAN, always 1",
+		append(wlanLabels, "ssid", "bssid", "standard"),
+		nil,
+	)
+	wlanEnabled = prometheus.NewDesc(
+		"fritzbox_wlan_enabled",
+		"WLAN is enabled (1) or disabled (0)",
+		wlanLabels,
+		nil,
+	)
+	wlanChannel = prometheus.NewDesc(
+		"fritzbox_wlan_channel",
+		"Channel the WLAN uses",
+		wlanLabels,
+		nil,
+	)
+	wlanAssociations = prometheus.NewDesc(
+		"fritzbox_wlan_associated_devices",
+		"Number of devices associated with the WLAN",
+		wlanLabels,
+		nil,
+	)
+	wlanClientSignalStrength = prometheus.NewDesc(
+		"fritzbox_wlan_client_signal_strength_percent",
+		"Signal strength of the WLAN client",
+		wlanClientLabels,
+		nil,
+	)
+	wlanClientSpeed = prometheus.NewDesc(
+		"fritzbox_wlan_client_speed_bits_per_second",
+		"Link speed of the WLAN client",
+		wlanClientLabels,
+		nil,
+	)
+
+	wlanDescs = []*prometheus.Desc{
+		wlanInfo,
+		wlanEnabled,
+		wlanChannel,
+		wlanAssociations,
+		wlanClientSignalStrength,
+		wlanClientSpeed,
+	}
+)
+
+// collectWLAN exports every WLAN of the FRITZ!Box (2.4 GHz, 5 GHz, 6 GHz and
+// guest network) with its associated devices. The WLANs are told apart by the
+// instance number of their WLANConfiguration service.
+func (fc *FritzboxCollector) collectWLAN(ctx context.Context, ch chan<- prometheus.Metric, root *fritzboxmetrics.Root) {
+	if !fc.WLAN {
+		return
+	}
+
+	for _, client := range wlanconfiguration.All(root, fritzboxmetrics.SourceTR64) {
+		labels := []string{fc.Gateway, strconv.Itoa(client.Service.Instance())}
+
+		info, err := client.GetInfo(ctx)
+		if err != nil {
+			reportCallError(client.Service.ServiceType, "GetInfo", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(wlanInfo, prometheus.GaugeValue, 1, append(labels, info.SSID, info.BSSID, info.Standard)...)
+		ch <- prometheus.MustNewConstMetric(wlanEnabled, prometheus.GaugeValue, boolToFloat(info.Enable), labels...)
+		ch <- prometheus.MustNewConstMetric(wlanChannel, prometheus.GaugeValue, float64(info.Channel), labels...)
+
+		total, err := client.GetTotalAssociations(ctx)
+		if err != nil {
+			reportCallError(client.Service.ServiceType, "GetTotalAssociations", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(wlanAssociations, prometheus.GaugeValue, float64(total.TotalAssociations), labels...)
+
+		devices, err := wlanDevices(ctx, client, total.TotalAssociations)
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool, len(devices))
+		for _, d := range devices {
+			if d.MACAddress == "" || seen[d.MACAddress] {
+				// A device may be listed twice while it roams between the bands
+				continue
+			}
+			seen[d.MACAddress] = true
+			clientLabels := append(labels, d.MACAddress, d.IPAddress)
+			ch <- prometheus.MustNewConstMetric(wlanClientSignalStrength, prometheus.GaugeValue, float64(d.SignalStrength), clientLabels...)
+			ch <- prometheus.MustNewConstMetric(wlanClientSpeed, prometheus.GaugeValue, float64(d.Speed)*1e6, clientLabels...)
+		}
+	}
+}
+
+// wlanDevices returns the devices associated with the WLAN. The list is
+// downloaded at once if the FRITZ!Box supports it, otherwise every device is read by its index.
+// Errors are reported.
+func wlanDevices(ctx context.Context, client *wlanconfiguration.Client, total uint16) ([]wlanconfiguration.Device, error) {
+	if _, ok := client.Service.Actions[wlanDeviceListPathAction]; ok {
+		devices, err := client.DeviceList(ctx)
+		if err == nil {
+			return devices, nil
+		}
+		reportCallError(client.Service.ServiceType, wlanDeviceListPathAction, err)
+	}
+
+	devices := make([]wlanconfiguration.Device, 0, total)
+	for index := uint16(0); index < total; index++ {
+		info, err := client.GetGenericAssociatedDeviceInfo(ctx, index)
+		if err != nil {
+			reportCallError(client.Service.ServiceType, "GetGenericAssociatedDeviceInfo", err)
+			return nil, err
+		}
+		devices = append(devices, wlanconfiguration.Device{
+			Index:          int(index),
+			MACAddress:     info.AssociatedDeviceMACAddress,
+			IPAddress:      info.AssociatedDeviceIPAddress,
+			AuthState:      info.AssociatedDeviceAuthState,
+			Speed:          int(info.XAVMDESpeed),
+			SignalStrength: int(info.XAVMDESignalStrength),
+		})
+	}
+	return devices, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+var wlanMetricNames = []string{
+	"fritzbox_wlan_associated_devices",
+	"fritzbox_wlan_channel",
+	"fritzbox_wlan_client_signal_strength_percent",
+	"fritzbox_wlan_client_speed_bits_per_second",
+	"fritzbox_wlan_enabled",
+	"fritzbox_wlan_info",
+}
+
+const emptyWLANDeviceList = `<?xml version="1.0" encoding="utf-8"?>
+<List><TotalNumber>0</TotalNumber></List>
+`
+
+// wlanInstance answers the actions of the WLANConfiguration service at the control URL
+func wlanInstance(s *fritzboxtest.Server, n int, info map[string]string, total int, devices string) {
+	control := "/upnp/control/wlanconfig" + strconv.Itoa(n)
+	path := "/wlandevicelist" + strconv.Itoa(n) + ".lua"
+	respond := func(outputs map[string]string) fritzboxtest.Handler {
+		return func(map[string]string) (map[string]string, error) {
+			return outputs, nil
+		}
+	}
+
+	// Outputs all WLANs have in common
+	for name, val := range map[string]string{
+		"NewMaxBitRate":               "Auto",
+		"NewBeaconType":               "11i",
+		"NewMACAddressControlEnabled": "0",
+		"NewBasicEncryptionModes":     "None",
+		"NewBasicAuthenticationMode":  "None",
+	} {
+		info[name] = val
+	}
+
+	s.HandleControl(control, "GetInfo", respond(info))
+	s.HandleControl(control, "GetTotalAssociations", respond(map[string]string{"NewTotalAssociations": strconv.Itoa(total)}))
+	s.HandleControl(control, wlanDeviceListPathAction, respond(map[string]string{"NewX_AVM-DE_WLANDeviceListPath": path}))
+	s.SetDocument(path, devices)
+}
+
+// handleWLAN sets up a 2.4 GHz WLAN without devices, a 5 GHz WLAN with the
+// given device list and a disabled guest WLAN
+func handleWLAN(s *fritzboxtest.Server, total int, devices string) {
+	wlanInstance(s, 1, map[string]string{"NewEnable": "1", "NewStatus": "Up", "NewChannel": "6", "NewSSID": "home", "NewStandard": "n", "NewBSSID": "02:00:00:00:00:12"},
+		0, emptyWLANDeviceList)
+	wlanInstance(s, 2, map[string]string{"NewEnable": "1", "NewStatus": "Up", "NewChannel": "36", "NewSSID": "home", "NewStandard": "ac", "NewBSSID": "02:00:00:00:00:11"},
+		total, devices)
+	wlanInstance(s, 3, map[string]string{"NewEnable": "0", "NewStatus": "Disabled", "NewChannel": "6", "NewSSID": "guest", "NewStandard": "n", "NewBSSID": "02:00:00:00:00:13"},
+		0, emptyWLANDeviceList)
+}
+
+func TestCollectWLAN(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleWLAN(s, 2, fritzboxtest.WLANDeviceList)
+	fc.WLAN = true
+
+	assertMetrics(t, fc, `
+# HELP fritzbox_wlan_associated_devices Number of devices associated with the WLAN
+# TYPE fritzbox_wlan_associated_devices gauge
+fritzbox_wlan_associated_devices{gateway="fritz.box",wlan="1"} 0
+fritzbox_wlan_associated_devices{gateway="fritz.box",wlan="2"} 2
+fritzbox_wlan_associated_devices{gateway="fritz.box",wlan="3"} 0
+# HELP fritzbox_wlan_channel Channel the WLAN uses
+# TYPE fritzbox_wlan_channel gauge
+fritzbox_wlan_channel{gateway="fritz.box",wlan="1"} 6
+fritzbox_wlan_channel{gateway="fritz.box",wlan="2"} 36
+fritzbox_wlan_channel{gateway="fritz.box",wlan="3"} 6
+# HELP fritzbox_wlan_client_signal_strength_percent Signal strength of the WLAN client
+# TYPE fritzbox_wlan_client_signal_strength_percent gauge
+fritzbox_wlan_client_signal_strength_percent{gateway="fritz.box",ip="192.168.178.21",mac="02:00:00:00:00:02",wlan="2"} 62
+fritzbox_wlan_client_signal_strength_percent{gateway="fritz.box",ip="192.168.178.22",mac="02:00:00:00:00:03",wlan="2"} 21
+# HELP fritzbox_wlan_client_speed_bits_per_second Link speed of the WLAN client
+# TYPE fritzbox_wlan_client_speed_bits_per_second gauge
+fritzbox_wlan_client_speed_bits_per_second{gateway="fritz.box",ip="192.168.178.21",mac="02:00:00:00:00:02",wlan="2"} 8.66e+08
+fritzbox_wlan_client_speed_bits_per_second{gateway="fritz.box",ip="192.168.178.22",mac="02:00:00:00:00:03",wlan="2"} 1.44e+08
+# HELP fritzbox_wlan_enabled WLAN is enabled (1) or disabled (0)
+# TYPE fritzbox_wlan_enabled gauge
+fritzbox_wlan_enabled{gateway="fritz.box",wlan="1"} 1
+fritzbox_wlan_enabled{gateway="fritz.box",wlan="2"} 1
+fritzbox_wlan_enabled{gateway="fritz.box",wlan="3"} 0
+# HELP fritzbox_wlan_info Information about the WLAN, always 1
+# TYPE fritzbox_wlan_info gauge
+fritzbox_wlan_info{bssid="02:00:00:00:00:11",gateway="fritz.box",ssid="home",standard="ac",wlan="2"} 1
+fritzbox_wlan_info{bssid="02:00:00:00:00:12",gateway="fritz.box",ssid="home",standard="n",wlan="1"} 1
+fritzbox_wlan_info{bssid="02:00:00:00:00:13",gateway="fritz.box",ssid="guest",standard="n",wlan="3"} 1
+`, wlanMetricNames...)
+}
+
+func TestCollectWLANDuplicate(t *testing.T) {
+	s, fc := newTestCollector(t)
+	handleWLAN(s, 2, `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<TotalNumber>2</TotalNumber>
+<Item><AssociatedDeviceIndex>0</AssociatedDeviceIndex><AssociatedDeviceMACAddress>02:00:00:00:00:02</AssociatedDeviceMACAddress><AssociatedDeviceIPAddress>192.168.178.21</AssociatedDeviceIPAddress><X_AVM-DE_Speed>866</X_AVM-DE_Speed><X_AVM-DE_SignalStrength>62</X_AVM-DE_SignalStrength></Item>
+<Item><AssociatedDeviceIndex>1</AssociatedDeviceIndex><AssociatedDeviceMACAddress>02:00:00:00:00:02</AssociatedDeviceMACAddress><AssociatedDeviceIPAddress>192.168.178.21</AssociatedDeviceIPAddress><X_AVM-DE_Speed>0</X_AVM-DE_Speed><X_AVM-DE_SignalStrength>0</X_AVM-DE_SignalStrength></Item>
+</List>
+`)
+	fc.WLAN = true
+
+	assertScrape(t, fc)
+	assertMetrics(t, fc, `
+# HELP fritzbox_wlan_client_signal_strength_percent Signal strength of the WLAN client
+# TYPE fritzbox_wlan_client_signal_strength_percent gauge
+fritzbox_wlan_client_signal_strength_percent{gateway="fritz.box",ip="192.168.178.21",mac="02:00:00:00:00:02",wlan="2"} 62
+`, "fritzbox_wlan_client_signal_strength_percent")
+}
@@ -0,0 +1,358 @@
+// Command scpdgen generates a typed Go client for a UPnP service from its SCPD XML.
+//
+// Usage:
+//
+//	scpdgen -scpd deviceinfoSCPD.xml -type urn:dslforum-org:service:DeviceInfo:1 -pkg deviceinfo -out ./deviceinfo
+package main
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type scpd struct {
+	Actions []struct {
+		Name      string `xml:"name"`
+		Arguments []struct {
+			Name                 string `xml:"name"`
+			Direction            string `xml:"direction"`
+			RelatedStateVariable string `xml:"relatedStateVariable"`
+		} `xml:"argumentList>argument"`
+	} `xml:"actionList>action"`
+	StateVariables []struct {
+		Name     string `xml:"name"`
+		DataType string `xml:"dataType"`
+	} `xml:"serviceStateTable>stateVariable"`
+}
+
+// goTypes maps the UPnP data types to the Go types used in the generated code.
+// Unknown data types are mapped to interface{}, their values are strings.
+var goTypes = map[string]string{
+	"string":  "string",
+	"char":    "string",
+	"uuid":    "string",
+	"uri":     "string",
+	"boolean": "bool",
+	"ui1":     "uint8",
+	"ui2":     "uint16",
+	"ui4":     "uint64", // AVM uses ui4 for values greater than 2^32
+	"ui8":     "uint64",
+	"i1":      "int8",
+	"i2":      "int16",
+	"i4":      "int64",
+	"i8":      "int64",
+	"int":     "int64",
+
+	"r4":         "float32",
+	"r8":         "float64",
+	"number":     "float64",
+	"float":      "float64",
+	"fixed.14.4": "float64",
+
+	"bin.base64": "[]byte",
+	"bin.hex":    "[]byte",
+
+	"date":        "time.Time",
+	"time":        "time.Time",
+	"time.tz":     "time.Time",
+	"dateTime":    "time.Time",
+	"dateTime.tz": "time.Time",
+}
+
+// counters64 are the state variables of the 64 bit counters AVM declares as
+// string, the same list as in fritzboxmetrics
+var counters64 = map[string]bool{
+	"X_AVM_DE_TotalBytesSent64":     true,
+	"X_AVM_DE_TotalBytesReceived64": true,
+	"X_AVM-DE_TotalBytesSent64":     true,
+	"X_AVM-DE_TotalBytesReceived64": true,
+}
+
+// goTypeFor returns the Go type of a state variable, matching the conversion of fritzboxmetrics
+func goTypeFor(stateVariable, dataType string) string {
+	// AVM declares its 64 bit counters like X_AVM_DE_TotalBytesSent64 as string,
+	// convertResult returns them as uint64 and an empty value as 0
+	if dataType == "string" && counters64[stateVariable] {
+		return "uint64"
+	}
+
+	goType, ok := goTypes[dataType]
+	if !ok {
+		return "interface{}"
+	}
+	return goType
+}
+
+type argument struct {
+	Name      string // Name in the SCPD, e.g. NewIndex
+	GoName    string // Exported Go name, e.g. Index
+	ParamName string // Parameter name, e.g. index
+	GoType    string
+	DataType  string
+}
+
+type action struct {
+	Name    string
+	GoName  string
+	Inputs  []argument
+	Outputs []argument
+}
+
+type service struct {
+	Package     string
+	ServiceType string
+	ServiceName string // e.g. WANCommonInterfaceConfig
+	SCPDFile    string
+	Actions     []action
+	UsesTime    bool
+}
+
+func main() {
+	scpdFile := flag.String("scpd", "", "The SCPD XML file to generate the client from")
+	serviceType := flag.String("type", "", "The service type, e.g. urn:dslforum-org:service:DeviceInfo:1")
+	pkg := flag.String("pkg", "", "The name of the generated package")
+	out := flag.String("out", "", "The output directory, defaults to the package name")
+	flag.Parse()
+
+	if *scpdFile == "" || *serviceType == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = *pkg
+	}
+
+	svc, err := loadService(*scpdFile, *serviceType, *pkg)
+	if err != nil {
+		log.Fatalf("could not load %s: %v", *scpdFile, err)
+	}
+
+	code, err := generate(svc)
+	if err != nil {
+		log.Fatalf("could not generate %s: %v", *pkg, err)
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatalf("could not create output directory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(*out, *pkg+".go"), code, 0644); err != nil {
+		log.Fatalf("could not write client: %v", err)
+	}
+}
+
+func loadService(file, serviceType, pkg string) (*service, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var desc scpd
+	if err := xml.Unmarshal(data, &desc); err != nil {
+		return nil, fmt.Errorf("could not decode XML: %w", err)
+	}
+
+	dataTypes := make(map[string]string)
+	for _, v := range desc.StateVariables {
+		dataTypes[v.Name] = v.DataType
+	}
+
+	svc := &service{
+		Package:     pkg,
+		ServiceType: serviceType,
+		ServiceName: serviceName(serviceType),
+		SCPDFile:    filepath.Base(file),
+	}
+	for _, a := range desc.Actions {
+		act := action{Name: a.Name, GoName: goName(a.Name)}
+		for _, arg := range a.Arguments {
+			dataType, ok := dataTypes[arg.RelatedStateVariable]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown state variable %s", a.Name, arg.RelatedStateVariable)
+			}
+			goType := goTypeFor(arg.RelatedStateVariable, dataType)
+			svc.UsesTime = svc.UsesTime || goType == "time.Time"
+
+			name := goName(strings.TrimPrefix(arg.Name, "New"))
+			generated := argument{
+				Name:      arg.Name,
+				GoName:    name,
+				ParamName: paramName(name),
+				GoType:    goType,
+				DataType:  dataType,
+			}
+			if arg.Direction == "in" {
+				act.Inputs = append(act.Inputs, generated)
+			} else {
+				act.Outputs = append(act.Outputs, generated)
+			}
+		}
+		svc.Actions = append(svc.Actions, act)
+	}
+	return svc, nil
+}
+
+// serviceName returns the name part of a service type,
+// e.g. WANCommonInterfaceConfig for urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1
+func serviceName(serviceType string) string {
+	parts := strings.Split(serviceType, ":")
+	if len(parts) < 2 {
+		return goName(serviceType)
+	}
+	return goName(parts[len(parts)-2])
+}
+
+// goName converts a UPnP name into an exported Go identifier,
+// e.g. XAVMDEGetHostListPath for X_AVM-DE_GetHostListPath
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// paramName converts an exported Go name into a parameter name, e.g. index for Index
+func paramName(name string) string {
+	runes := []rune(name)
+	// Lower the leading upper case run, e.g. MACAddress -> macAddress
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	param := string(runes)
+	if token.IsKeyword(param) || param == "ctx" || param == "c" {
+		param += "Arg"
+	}
+	return param
+}
+
+func generate(svc *service) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, svc); err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("could not format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return code, nil
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by scpdgen from {{.SCPDFile}}. DO NOT EDIT.
+
+// Package {{.Package}} is a typed client for the {{.ServiceName}} service.
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of {{.ServiceName}}
+const ServiceType = "{{.ServiceType}}"
+
+// Client calls the actions of a {{.ServiceName}} service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first {{.ServiceName}} service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+{{range $a := .Actions}}
+// {{$a.GoName}}Response are the output arguments of {{$a.Name}}
+type {{$a.GoName}}Response struct {
+{{- range $a.Outputs}}
+	{{.GoName}} {{.GoType}} // {{.Name}} ({{.DataType}})
+{{- end}}
+}
+
+// {{$a.GoName}} calls {{$a.Name}}
+func (c *Client) {{$a.GoName}}(ctx context.Context{{range $a.Inputs}}, {{.ParamName}} {{.GoType}}{{end}}) ({{$a.GoName}}Response, error) {
+	var resp {{$a.GoName}}Response
+{{- if $a.Inputs}}
+	args := map[string]interface{}{
+{{- range $a.Inputs}}
+		"{{.Name}}": {{.ParamName}},
+{{- end}}
+	}
+{{- else}}
+	var args map[string]interface{}
+{{- end}}
+
+	{{if $a.Outputs}}action{{else}}_{{end}}, {{if $a.Outputs}}res{{else}}_{{end}}, err := c.call(ctx, "{{$a.Name}}", args)
+	if err != nil {
+		return resp, err
+	}
+{{- if $a.Outputs}}
+
+	dec := action.NewOutputDecoder(res)
+{{- range $a.Outputs}}
+	dec.Decode("{{.Name}}", &resp.{{.GoName}})
+{{- end}}
+	return resp, dec.Err()
+{{- else}}
+	return resp, nil
+{{- end}}
+}
+{{end}}`))
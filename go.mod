@@ -3,7 +3,6 @@ module github.com/mxschmitt/fritzbox_exporter
 go 1.15
 
 require (
-	github.com/123Haynes/go-http-digest-auth-client v0.3.1-0.20171226204513-4c2ff1556cab
 	github.com/mxschmitt/golang-env-struct v0.0.0-20181017075525-0c54aeca8397
 	github.com/prometheus/client_golang v1.9.0
 )
@@ -0,0 +1,111 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+)
+
+// ahaPath is the AHA HTTP interface for smart home devices
+const ahaPath = "/webservices/homeautoswitch.lua"
+
+// AHADevice is a smart home device as listed by getdevicelistinfos.
+// Values the device doesn't support are nil. The units are the ones of the
+// AHA interface, see the methods for converted values.
+type AHADevice struct {
+	AIN          string `xml:"identifier,attr"` // Actor identification number, e.g. 08761 0000434
+	ID           string `xml:"id,attr"`
+	Functions    int    `xml:"functionbitmask,attr"`
+	FWVersion    string `xml:"fwversion,attr"`
+	Manufacturer string `xml:"manufacturer,attr"`
+	ProductName  string `xml:"productname,attr"`
+
+	Name       string `xml:"name"`
+	Present    bool   `xml:"present"`
+	Battery    *int   `xml:"battery"` // Percent
+	BatteryLow *bool  `xml:"batterylow"`
+
+	Switch *struct {
+		State string `xml:"state"` // 1 on, 0 off, empty if unknown
+	} `xml:"switch"`
+	PowerMeter *struct {
+		Power   int `xml:"power"`   // mW
+		Energy  int `xml:"energy"`  // Wh
+		Voltage int `xml:"voltage"` // mV
+	} `xml:"powermeter"`
+	Temperature *struct {
+		Celsius int `xml:"celsius"` // 0.1 °C
+	} `xml:"temperature"`
+	Thermostat *struct {
+		Current    int   `xml:"tist"`  // 0.5 °C
+		Target     int   `xml:"tsoll"` // 0.5 °C, 253 off, 254 on
+		WindowOpen bool  `xml:"windowopenactiv"`
+		Battery    *int  `xml:"battery"`
+		BatteryLow *bool `xml:"batterylow"`
+	} `xml:"hkr"`
+}
+
+// Thermostat temperature values with a special meaning
+const (
+	ThermostatOff = 253
+	ThermostatOn  = 254
+)
+
+// AHADevices returns all smart home devices known to the FRITZ!Box
+func (s *Session) AHADevices(ctx context.Context) ([]AHADevice, error) {
+	data, err := s.Get(ctx, ahaPath, url.Values{"switchcmd": {"getdevicelistinfos"}})
+	if err != nil {
+		return nil, fmt.Errorf("could not get device list: %w", err)
+	}
+
+	var list struct {
+		Devices []AHADevice `xml:"device"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("could not decode device list: %w", err)
+	}
+	return list.Devices, nil
+}
+
+// SwitchOn returns the state of the switch, ok is false if the device has no switch or the state is unknown
+func (d *AHADevice) SwitchOn() (on bool, ok bool) {
+	if d.Switch == nil || d.Switch.State == "" {
+		return false, false
+	}
+	return d.Switch.State == "1", true
+}
+
+// BatteryPercent returns the battery level of the device or its thermostat
+func (d *AHADevice) BatteryPercent() (int, bool) {
+	if d.Battery != nil {
+		return *d.Battery, true
+	}
+	if d.Thermostat != nil && d.Thermostat.Battery != nil {
+		return *d.Thermostat.Battery, true
+	}
+	return 0, false
+}
+
+// ThermostatCelsius converts a thermostat temperature in 0.5 °C steps.
+// ok is false for the special values ThermostatOff and ThermostatOn.
+func ThermostatCelsius(v int) (float64, bool) {
+	if v == ThermostatOff || v == ThermostatOn {
+		return 0, false
+	}
+	return float64(v) / 2, true
+}
@@ -0,0 +1,102 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// StateVariable returns the state variable with the given name, e.g. ConnectionStatus
+func (s *Service) StateVariable(name string) (*StateVariable, bool) {
+	for _, v := range s.StateVariables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// IsAllowed reports whether the string representation of a value
+// is in the allowed value list of the variable. Variables without a list allow all values.
+func (v *StateVariable) IsAllowed(value string) bool {
+	if len(v.AllowedValues) == 0 {
+		return true
+	}
+	for _, allowed := range v.AllowedValues {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the string representation of an input value
+// against the allowed value list and range of the variable
+func (v *StateVariable) validate(value string) error {
+	if !v.IsAllowed(value) {
+		return fmt.Errorf("value %q is not one of %s", value, strings.Join(v.AllowedValues, ", "))
+	}
+	if v.AllowedRange == nil {
+		return nil
+	}
+	return v.AllowedRange.validate(value)
+}
+
+func (r *AllowedValueRange) validate(value string) error {
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("value %q is not numeric", value)
+	}
+
+	min, hasMin, err := parseBound(r.Minimum)
+	if err != nil {
+		return err
+	}
+	max, hasMax, err := parseBound(r.Maximum)
+	if err != nil {
+		return err
+	}
+	step, hasStep, err := parseBound(r.Step)
+	if err != nil {
+		return err
+	}
+
+	if hasMin && val < min {
+		return fmt.Errorf("value %s is less than the minimum %s", value, r.Minimum)
+	}
+	if hasMax && val > max {
+		return fmt.Errorf("value %s is greater than the maximum %s", value, r.Maximum)
+	}
+	if hasStep && step > 0 && math.Mod(val-min, step) != 0 {
+		return fmt.Errorf("value %s is not a multiple of the step %s", value, r.Step)
+	}
+	return nil
+}
+
+// parseBound parses a bound of an allowedValueRange, an empty bound is unset
+func parseBound(bound string) (float64, bool, error) {
+	bound = strings.TrimSpace(bound)
+	if bound == "" {
+		return 0, false, nil
+	}
+	f, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid bound %q in allowedValueRange", bound)
+	}
+	return f, true, nil
+}
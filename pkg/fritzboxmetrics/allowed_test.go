@@ -0,0 +1,53 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "testing"
+
+func TestStateVariableValidate(t *testing.T) {
+	status := &StateVariable{Name: "ConnectionStatus", DataType: "string", AllowedValues: []string{"Unconfigured", "Connecting", "Connected", "Disconnected"}}
+	index := &StateVariable{Name: "Index", DataType: "ui2", AllowedRange: &AllowedValueRange{Minimum: "0", Maximum: "100"}}
+	channel := &StateVariable{Name: "Channel", DataType: "ui1", AllowedRange: &AllowedValueRange{Minimum: "36", Maximum: "64", Step: "4"}}
+	free := &StateVariable{Name: "SSID", DataType: "string"}
+	invalid := &StateVariable{Name: "Broken", DataType: "ui1", AllowedRange: &AllowedValueRange{Minimum: "low"}}
+
+	for _, tc := range []struct {
+		variable *StateVariable
+		value    string
+		ok       bool
+	}{
+		{status, "Connected", true},
+		{status, "connected", false},
+		{status, "Up", false},
+		{index, "0", true},
+		{index, "100", true},
+		{index, "-1", false},
+		{index, "101", false},
+		{index, "ten", false},
+		{channel, "36", true},
+		{channel, "40", true},
+		{channel, "64", true},
+		{channel, "38", false},
+		{channel, "32", false},
+		{channel, "68", false},
+		{free, "anything", true},
+		{invalid, "1", false},
+	} {
+		err := tc.variable.validate(tc.value)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s %q: got %v, want ok %v", tc.variable.Name, tc.value, err, tc.ok)
+		}
+	}
+}
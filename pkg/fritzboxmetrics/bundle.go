@@ -0,0 +1,97 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fetchFunc returns the description document with the given path (e.g. /igddesc.xml)
+type fetchFunc func(ctx context.Context, path string) ([]byte, error)
+
+// BundleKey identifies the device and firmware a Bundle was downloaded from.
+// The descriptions only change with a firmware update.
+type BundleKey struct {
+	UDN             string `json:"udn"`
+	FirmwareVersion string `json:"firmware_version"`
+}
+
+// Bundle holds the original description XMLs of a device indexed by their path
+type Bundle struct {
+	BundleKey
+	Documents map[string]string `json:"documents"`
+}
+
+// Bundle returns the descriptions the tree was built from
+func (r *Root) Bundle() *Bundle {
+	return r.bundle
+}
+
+func (r *Root) bundleKey() BundleKey {
+	return BundleKey{
+		UDN:             r.Device.UDN,
+		FirmwareVersion: r.SystemVersion.Display,
+	}
+}
+
+func (b *Bundle) fetch(_ context.Context, path string) ([]byte, error) {
+	doc, ok := b.Documents[path]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing in the bundle", path)
+	}
+	return []byte(doc), nil
+}
+
+// ReadBundleFile reads a bundle written by WriteFile
+func ReadBundleFile(path string) (*Bundle, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle: %w", err)
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("could not decode bundle: %w", err)
+	}
+	return &b, nil
+}
+
+// WriteFile writes the bundle as JSON. The file is replaced atomically.
+func (b *Bundle) WriteFile(path string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("could not encode bundle: %w", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write bundle: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write bundle: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,165 @@
+package fritzboxmetrics_test
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+// loadBundle starts a fake FRITZ!Box and returns a client with the bundle of its services
+func loadBundle(t *testing.T) (*fritzboxtest.Server, *fritzboxmetrics.Client, *fritzboxmetrics.Bundle) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	t.Cleanup(s.Close)
+
+	client := fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: testUsername, Password: testPassword})
+	root, err := client.LoadServices(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+	return s, client, root.Bundle()
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	s, client, bundle := loadBundle(t)
+
+	want := fritzboxmetrics.BundleKey{
+		UDN:             "uuid:739f2409-bccb-40e7-8e6c-3431C4000001",
+		FirmwareVersion: "154.07.29",
+	}
+	if bundle.BundleKey != want {
+		t.Errorf("got key %+v, want %+v", bundle.BundleKey, want)
+	}
+	for _, path := range []string{"/igddesc.xml", "/tr64desc.xml", "/deviceinfoSCPD.xml"} {
+		if bundle.Documents[path] == "" {
+			t.Errorf("fetched document %s is missing in the bundle", path)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	if err := bundle.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	// Overwriting replaces the file
+	if err := bundle.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if files, _ := filepath.Glob(path + ".*"); len(files) != 0 {
+		t.Errorf("temporary files left behind: %v", files)
+	}
+
+	read, err := fritzboxmetrics.ReadBundleFile(path)
+	if err != nil {
+		t.Fatalf("ReadBundleFile: %v", err)
+	}
+	if !reflect.DeepEqual(read, bundle) {
+		t.Errorf("read bundle differs from the written one")
+	}
+
+	fetches := make(map[string]int)
+	for path := range bundle.Documents {
+		fetches[path] = s.Fetches(path)
+	}
+
+	root, err := client.LoadServicesFromBundle(context.Background(), s.Host(), s.Port(), read)
+	if err != nil {
+		t.Fatalf("LoadServicesFromBundle: %v", err)
+	}
+	for path, n := range fetches {
+		if got := s.Fetches(path); got != n {
+			t.Errorf("%s fetched %d times while loading from the bundle", path, got-n)
+		}
+	}
+	if root.Bundle() != read {
+		t.Errorf("services tree does not keep the bundle it was loaded from")
+	}
+
+	for _, tc := range []struct {
+		source      fritzboxmetrics.ServiceSource
+		serviceType string
+		instances   int
+	}{
+		{fritzboxmetrics.SourceIGD, wanService, 1},
+		{fritzboxmetrics.SourceTR64, hostsService, 1},
+		{fritzboxmetrics.SourceTR64, "urn:dslforum-org:service:WLANConfiguration:1", 3},
+	} {
+		if got := len(root.ServicesByType(tc.source, tc.serviceType)); got != tc.instances {
+			t.Errorf("got %d instances of %s, want %d", got, tc.serviceType, tc.instances)
+		}
+	}
+
+	// The services loaded from the bundle are called on the device
+	s.SetResponse(hostsService, "GetHostNumberOfEntries", map[string]string{"NewHostNumberOfEntries": "7"})
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries")
+	res, err := a.Call()
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if got := res["HostNumberOfEntries"]; got != uint64(7) {
+		t.Errorf("got %v (%T), want 7", got, got)
+	}
+}
+
+func TestLoadServicesFromBundleMissingDocument(t *testing.T) {
+	s, client, bundle := loadBundle(t)
+
+	delete(bundle.Documents, "/tr64desc.xml")
+	if _, err := client.LoadServicesFromBundle(context.Background(), s.Host(), s.Port(), bundle); err == nil {
+		t.Error("loading an incomplete bundle succeeded")
+	}
+}
+
+func TestReadBundleFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := fritzboxmetrics.ReadBundleFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("reading a missing file succeeded")
+	}
+
+	path := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fritzboxmetrics.ReadBundleFile(path); err == nil {
+		t.Error("reading invalid JSON succeeded")
+	}
+}
+
+func TestLoadBundleKey(t *testing.T) {
+	s, client, bundle := loadBundle(t)
+
+	key, err := client.LoadBundleKey(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadBundleKey: %v", err)
+	}
+	if key != bundle.BundleKey {
+		t.Errorf("got key %+v, want %+v", key, bundle.BundleKey)
+	}
+
+	s.SetDocument("/tr64desc.xml", strings.Replace(bundle.Documents["/tr64desc.xml"], "154.07.29", "154.07.50", 1))
+	key, err = client.LoadBundleKey(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadBundleKey: %v", err)
+	}
+	if key.FirmwareVersion != "154.07.50" || key.UDN != bundle.UDN {
+		t.Errorf("got key %+v after the firmware update", key)
+	}
+}
@@ -0,0 +1,283 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout is the per-request timeout of a Client without an explicit timeout
+var DefaultTimeout = 10 * time.Second
+
+// ClientOptions configure a Client
+type ClientOptions struct {
+	Username string
+	Password string
+	Timeout  time.Duration // Timeout per HTTP request, DefaultTimeout if zero
+
+	// UseTLS sends the TR-064 SOAP calls to the security port advertised by the device.
+	// The descriptions are still loaded over plain HTTP, and the UPnP IGD services,
+	// which the device offers without authentication on the plain port only, stay there.
+	UseTLS    bool
+	TLSConfig *tls.Config // See NewTLSConfig
+
+	// Transport sends the HTTP requests, e.g. a Recorder or ReplayTransport.
+	// A transport with keep-alive and TLSConfig is used if nil.
+	Transport http.RoundTripper
+}
+
+// ClientStats are counters about the requests a Client has sent
+type ClientStats struct {
+	Requests       uint64 // HTTP requests sent, including retries
+	Retries        uint64 // Requests which had to be repeated after an authentication challenge
+	AuthChallenges uint64 // Digest challenges received from the device
+}
+
+// Client is a long-lived HTTP client for a device.
+// It keeps connections alive and caches the digest authentication
+// challenge, so that subsequent calls authenticate on the first try.
+type Client struct {
+	username string
+	password string
+	useTLS   bool
+
+	httpClient *http.Client
+
+	mu        sync.Mutex // protects challenge and nc
+	challenge *digestChallenge
+	nc        uint32
+
+	requests       uint64
+	retries        uint64
+	authChallenges uint64
+}
+
+// NewClient creates a new Client
+func NewClient(opts ClientOptions) *Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	transport := opts.Transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConnsPerHost = 4
+		if opts.TLSConfig != nil {
+			t.TLSClientConfig = opts.TLSConfig
+		}
+		transport = t
+	}
+
+	return &Client{
+		username: opts.Username,
+		password: opts.Password,
+		useTLS:   opts.UseTLS,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}
+}
+
+// Stats returns the request counters of the client
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		Requests:       atomic.LoadUint64(&c.requests),
+		Retries:        atomic.LoadUint64(&c.retries),
+		AuthChallenges: atomic.LoadUint64(&c.authChallenges),
+	}
+}
+
+// Do sends the request with digest authentication.
+// A cached challenge is used right away; if the device answers with a new
+// challenge (e.g. because the nonce became stale) the request is sent once more.
+// The request body has to be replayable via req.GetBody.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	atomic.AddUint64(&c.requests, 1)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	atomic.AddUint64(&c.authChallenges, 1)
+	challenge, err := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse digest challenge: %w", err)
+	}
+
+	c.mu.Lock()
+	c.challenge = challenge
+	c.nc = 0
+	c.mu.Unlock()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("could not rewind body: %w", err)
+		}
+	}
+	if err := c.authorize(retry); err != nil {
+		return nil, err
+	}
+
+	atomic.AddUint64(&c.requests, 1)
+	atomic.AddUint64(&c.retries, 1)
+	return c.httpClient.Do(retry)
+}
+
+// authorize sets the Authorization header if a challenge is cached
+func (c *Client) authorize(req *http.Request) error {
+	c.mu.Lock()
+	challenge := c.challenge
+	c.nc++
+	nc := c.nc
+	c.mu.Unlock()
+
+	if challenge == nil {
+		return nil
+	}
+
+	auth, err := challenge.authorization(req, c.username, c.password, nc)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", auth)
+	return nil
+}
+
+// get fetches the given URL and returns the response body.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create new request: %w", err)
+	}
+
+	atomic.AddUint64(&c.requests, 1)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data := new(bytes.Buffer)
+	if _, err := data.ReadFrom(resp.Body); err != nil {
+		return nil, fmt.Errorf("could not read body: %w", err)
+	}
+	return data.Bytes(), nil
+}
+
+// LoadServices loads the services tree from a device.
+// All calls on the returned tree are sent through the client.
+// The downloaded descriptions are available via Root.Bundle.
+func (c *Client) LoadServices(ctx context.Context, device string, port uint16) (*Root, error) {
+	baseURL := fmt.Sprintf("http://%s:%d", device, port)
+	bundle := &Bundle{Documents: make(map[string]string)}
+
+	return c.loadServices(ctx, device, port, bundle, func(ctx context.Context, path string) ([]byte, error) {
+		data, err := c.get(ctx, baseURL+path)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Documents[path] = string(data)
+		return data, nil
+	})
+}
+
+// LoadServicesFromBundle builds the services tree from previously downloaded descriptions.
+// No descriptions are fetched from the device, but calls on the returned tree are
+// sent to the device through the client.
+func (c *Client) LoadServicesFromBundle(ctx context.Context, device string, port uint16, bundle *Bundle) (*Root, error) {
+	return c.loadServices(ctx, device, port, bundle, bundle.fetch)
+}
+
+func (c *Client) loadServices(ctx context.Context, device string, port uint16, bundle *Bundle, fetch fetchFunc) (*Root, error) {
+	root := &Root{
+		BaseURL:  fmt.Sprintf("http://%s:%d", device, port),
+		Username: c.username,
+		Password: c.password,
+		Timeout:  c.httpClient.Timeout,
+		client:   c,
+		fetch:    fetch,
+		bundle:   bundle,
+	}
+
+	if err := root.load(ctx); err != nil {
+		return nil, fmt.Errorf("could not load root element: %w", err)
+	}
+
+	rootTr64 := &Root{
+		BaseURL:  fmt.Sprintf("http://%s:%d", device, port),
+		Username: c.username,
+		Password: c.password,
+		Timeout:  c.httpClient.Timeout,
+		client:   c,
+		fetch:    fetch,
+		bundle:   bundle,
+	}
+
+	if err := rootTr64.loadTr64(ctx); err != nil {
+		return nil, fmt.Errorf("could not load Tr64: %w", err)
+	}
+	bundle.BundleKey = rootTr64.bundleKey()
+
+	if c.useTLS {
+		if err := rootTr64.enableTLS(ctx, device); err != nil {
+			return nil, fmt.Errorf("could not enable TLS: %w", err)
+		}
+	}
+
+	// The IGD services take precedence in Services, use
+	// Root.Service or Root.ServicesByType to pick a source explicitly
+	for _, v := range rootTr64.Instances {
+		root.addService(v)
+	}
+
+	return root, nil
+}
+
+// LoadBundleKey fetches only tr64desc.xml to determine whether a cached bundle is still valid.
+func (c *Client) LoadBundleKey(ctx context.Context, device string, port uint16) (BundleKey, error) {
+	data, err := c.get(ctx, fmt.Sprintf("http://%s:%d/tr64desc.xml", device, port))
+	if err != nil {
+		return BundleKey{}, fmt.Errorf("could not fetch tr64desc.xml: %w", err)
+	}
+
+	var root Root
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return BundleKey{}, fmt.Errorf("could not decode XML: %w", err)
+	}
+	return root.bundleKey(), nil
+}
@@ -0,0 +1,198 @@
+package fritzboxmetrics_test
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+// soapExchange is a SOAP request as sent by the client and the status of its response
+type soapExchange struct {
+	authorization string
+	body          string
+	status        int
+}
+
+// soapRecorder records the SOAP requests a client sends
+type soapRecorder struct {
+	mu        sync.Mutex
+	exchanges []soapExchange
+}
+
+func (r *soapRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || req.Method != http.MethodPost {
+		return resp, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, soapExchange{req.Header.Get("Authorization"), string(body), resp.StatusCode})
+	return resp, nil
+}
+
+// take returns the exchanges recorded since the last call
+func (r *soapRecorder) take() []soapExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.exchanges
+	r.exchanges = nil
+	return e
+}
+
+var ncPattern = regexp.MustCompile(`nc=([0-9a-f]{8})`)
+
+func nonceCount(e soapExchange) string {
+	if m := ncPattern.FindStringSubmatch(e.authorization); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// newRecordedClient starts a fake FRITZ!Box and loads its services with a recorded client
+func newRecordedClient(t *testing.T) (*fritzboxtest.Server, *fritzboxmetrics.Client, *fritzboxmetrics.Root, *soapRecorder) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	t.Cleanup(s.Close)
+
+	rec := &soapRecorder{}
+	client := fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: testUsername, Password: testPassword, Transport: rec})
+	root, err := client.LoadServices(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+	return s, client, root, rec
+}
+
+func TestClientReusesChallenge(t *testing.T) {
+	s, client, root, rec := newRecordedClient(t)
+	s.SetResponse(hostsService, "GetHostNumberOfEntries", map[string]string{"NewHostNumberOfEntries": "3"})
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries")
+
+	before := client.Stats()
+	const calls = 5
+	for i := 0; i < calls; i++ {
+		if _, err := a.Call(); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+
+	exchanges := rec.take()
+	if len(exchanges) != calls+1 {
+		t.Fatalf("got %d requests for %d calls, want a single authentication challenge", len(exchanges), calls)
+	}
+	if exchanges[0].status != http.StatusUnauthorized || exchanges[0].authorization != "" {
+		t.Errorf("first request = %+v, want an unauthenticated request answered with 401", exchanges[0])
+	}
+	for i, e := range exchanges[1:] {
+		if e.status != http.StatusOK {
+			t.Errorf("request %d got status %d", i+1, e.status)
+		}
+		if nc, want := nonceCount(e), []string{"00000001", "00000002", "00000003", "00000004", "00000005"}[i]; nc != want {
+			t.Errorf("request %d has nonce count %q, want %s", i+1, nc, want)
+		}
+	}
+
+	after := client.Stats()
+	if got := after.Requests - before.Requests; got != calls+1 {
+		t.Errorf("counted %d requests, want %d", got, calls+1)
+	}
+	if got := after.Retries - before.Retries; got != 1 {
+		t.Errorf("counted %d retries, want 1", got)
+	}
+	if got := after.AuthChallenges - before.AuthChallenges; got != 1 {
+		t.Errorf("counted %d challenges, want 1", got)
+	}
+}
+
+func TestClientStaleNonce(t *testing.T) {
+	s, client, root, rec := newRecordedClient(t)
+	var got []string
+	s.Handle(hostsService, "GetGenericHostEntry", func(args map[string]string) (map[string]string, error) {
+		got = append(got, args["NewIndex"])
+		return map[string]string{"NewMACAddress": "02:00:00:00:00:02"}, nil
+	})
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetGenericHostEntry")
+
+	if _, err := a.CallWithArgs(map[string]interface{}{"NewIndex": 1}); err != nil {
+		t.Fatalf("CallWithArgs: %v", err)
+	}
+	rec.take()
+
+	s.ExpireNonce()
+	before := client.Stats()
+	if _, err := a.CallWithArgs(map[string]interface{}{"NewIndex": 2}); err != nil {
+		t.Fatalf("CallWithArgs with a stale nonce: %v", err)
+	}
+
+	exchanges := rec.take()
+	if len(exchanges) != 2 {
+		t.Fatalf("got %d requests, want exactly one retry", len(exchanges))
+	}
+	if exchanges[0].status != http.StatusUnauthorized || exchanges[1].status != http.StatusOK {
+		t.Errorf("got statuses %d and %d, want 401 and 200", exchanges[0].status, exchanges[1].status)
+	}
+	if exchanges[1].body != exchanges[0].body || !strings.Contains(exchanges[1].body, "<NewIndex>2</NewIndex>") {
+		t.Errorf("retry sent body\n%s\nwant\n%s", exchanges[1].body, exchanges[0].body)
+	}
+	if nc := nonceCount(exchanges[1]); nc != "00000001" {
+		t.Errorf("retry has nonce count %q, want the count of the new nonce to start at 00000001", nc)
+	}
+	if len(got) != 2 || got[1] != "2" {
+		t.Errorf("device got indexes %v, want [1 2]", got)
+	}
+
+	after := client.Stats()
+	if after.Requests-before.Requests != 2 || after.Retries-before.Retries != 1 || after.AuthChallenges-before.AuthChallenges != 1 {
+		t.Errorf("stats changed from %+v to %+v, want 2 requests, 1 retry and 1 challenge", before, after)
+	}
+}
+
+func TestClientWrongPassword(t *testing.T) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	defer s.Close()
+	s.SetResponse(hostsService, "GetHostNumberOfEntries", map[string]string{"NewHostNumberOfEntries": "3"})
+
+	rec := &soapRecorder{}
+	client := fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: testUsername, Password: "wrong", Transport: rec})
+	root, err := client.LoadServices(context.Background(), s.Host(), s.Port())
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+	if _, err := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries").Call(); err == nil {
+		t.Error("call with a wrong password succeeded")
+	}
+	// The retry is answered with 401, too, but not retried again
+	if n := len(rec.take()); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
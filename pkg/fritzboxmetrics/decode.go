@@ -0,0 +1,239 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrMissingOutput will be thrown if a result lacks an output argument
+var ErrMissingOutput = errors.New("missing output argument")
+
+// DecodeOutput stores the value of the output argument with the given name in dst.
+// dst has to be a pointer to string, bool, a sized integer type, float32, float64,
+// []byte, time.Time or interface{}.
+// Integers are range checked against the type of dst.
+func (a *Action) DecodeOutput(res Result, name string, dst interface{}) error {
+	val, ok := a.Output(res, name)
+	if !ok {
+		return fmt.Errorf("%w: %s of %s", ErrMissingOutput, name, a.Name)
+	}
+
+	if err := decodeValue(val, dst); err != nil {
+		return fmt.Errorf("could not decode %s of %s: %w", name, a.Name, err)
+	}
+	return nil
+}
+
+// OutputDecoder decodes the output arguments of a result one by one.
+// Outputs the SCPD of the device doesn't declare, e.g. X_AVM-DE_* outputs on
+// older firmware, leave their destination at the zero value. Declared outputs
+// missing in the result are reported by Err and Missing.
+type OutputDecoder struct {
+	action  *Action
+	res     Result
+	errs    DecodeErrors
+	missing []string
+}
+
+// NewOutputDecoder returns a decoder for the outputs of the result of a call of the action
+func (a *Action) NewOutputDecoder(res Result) *OutputDecoder {
+	return &OutputDecoder{action: a, res: res}
+}
+
+// Decode stores the value of the output argument with the given name in dst,
+// see DecodeOutput. Errors are collected and returned by Err.
+func (d *OutputDecoder) Decode(name string, dst interface{}) {
+	if _, ok := d.action.ArgumentMap[name]; !ok {
+		return
+	}
+	err := d.action.DecodeOutput(d.res, name, dst)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrMissingOutput) {
+		d.missing = append(d.missing, name)
+	}
+	d.errs = append(d.errs, err)
+}
+
+// Missing returns the names of the declared outputs the result lacks
+func (d *OutputDecoder) Missing() []string {
+	return d.missing
+}
+
+// Err returns the errors of all outputs which could not be decoded, nil if there were none
+func (d *OutputDecoder) Err() error {
+	switch len(d.errs) {
+	case 0:
+		return nil
+	case 1:
+		return d.errs[0]
+	default:
+		return d.errs
+	}
+}
+
+// DecodeErrors are the errors of several output arguments
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target, e.g. ErrMissingOutput
+func (e DecodeErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeValue(val interface{}, dst interface{}) error {
+	switch d := dst.(type) {
+	case *interface{}:
+		*d = val
+		return nil
+	case *string:
+		v, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", val)
+		}
+		*d = v
+		return nil
+	case *bool:
+		v, ok := val.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, got %T", val)
+		}
+		*d = v
+		return nil
+	case *time.Time:
+		v, ok := val.(time.Time)
+		if !ok {
+			return fmt.Errorf("expected time.Time, got %T", val)
+		}
+		*d = v
+		return nil
+	case *[]byte:
+		v, ok := val.([]byte)
+		if !ok {
+			return fmt.Errorf("expected []byte, got %T", val)
+		}
+		*d = v
+		return nil
+	case *float64:
+		v, ok := val.(float64)
+		if !ok {
+			return fmt.Errorf("expected float, got %T", val)
+		}
+		*d = v
+		return nil
+	case *float32:
+		v, ok := val.(float64)
+		if !ok {
+			return fmt.Errorf("expected float, got %T", val)
+		}
+		if math.Abs(v) > math.MaxFloat32 {
+			return fmt.Errorf("value %g overflows float32", v)
+		}
+		*d = float32(v)
+		return nil
+	case *uint8, *uint16, *uint32, *uint64:
+		return decodeUint(val, d)
+	case *int8, *int16, *int32, *int64:
+		return decodeInt(val, d)
+	default:
+		return fmt.Errorf("unsupported destination type %T", dst)
+	}
+}
+
+func decodeUint(val interface{}, dst interface{}) error {
+	v, ok := val.(uint64)
+	if !ok {
+		return fmt.Errorf("expected unsigned integer, got %T", val)
+	}
+
+	var max uint64
+	switch dst.(type) {
+	case *uint8:
+		max = math.MaxUint8
+	case *uint16:
+		max = math.MaxUint16
+	case *uint32:
+		max = math.MaxUint32
+	default:
+		max = math.MaxUint64
+	}
+	if v > max {
+		return fmt.Errorf("value %d overflows %T", v, dst)
+	}
+
+	switch d := dst.(type) {
+	case *uint8:
+		*d = uint8(v)
+	case *uint16:
+		*d = uint16(v)
+	case *uint32:
+		*d = uint32(v)
+	case *uint64:
+		*d = v
+	}
+	return nil
+}
+
+func decodeInt(val interface{}, dst interface{}) error {
+	v, ok := val.(int64)
+	if !ok {
+		return fmt.Errorf("expected integer, got %T", val)
+	}
+
+	var min, max int64
+	switch dst.(type) {
+	case *int8:
+		min, max = math.MinInt8, math.MaxInt8
+	case *int16:
+		min, max = math.MinInt16, math.MaxInt16
+	case *int32:
+		min, max = math.MinInt32, math.MaxInt32
+	default:
+		min, max = math.MinInt64, math.MaxInt64
+	}
+	if v < min || v > max {
+		return fmt.Errorf("value %d overflows %T", v, dst)
+	}
+
+	switch d := dst.(type) {
+	case *int8:
+		*d = int8(v)
+	case *int16:
+		*d = int16(v)
+	case *int32:
+		*d = int32(v)
+	case *int64:
+		*d = v
+	}
+	return nil
+}
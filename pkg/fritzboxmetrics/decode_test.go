@@ -0,0 +1,139 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testAction() *Action {
+	a := &Action{Name: "GetInfo", ArgumentMap: make(map[string]*Argument)}
+	for name, v := range map[string]*StateVariable{
+		"NewModelName":       {Name: "ModelName", DataType: "string"},
+		"NewUpTime":          {Name: "UpTime", DataType: "ui4"},
+		"NewEnable":          {Name: "Enable", DataType: "boolean"},
+		"NewX_AVM-DE_Public": {Name: "X_AVM-DE_Public", DataType: "boolean"},
+	} {
+		a.ArgumentMap[name] = &Argument{Name: name, Direction: "out", RelatedStateVariable: v.Name, StateVariable: v}
+	}
+	return a
+}
+
+func TestDecodeOutput(t *testing.T) {
+	a := testAction()
+	res := Result{"ModelName": "FRITZ!Box 7590", "UpTime": uint64(70000)}
+
+	var model string
+	if err := a.DecodeOutput(res, "NewModelName", &model); err != nil || model != "FRITZ!Box 7590" {
+		t.Errorf("got %q, %v, want FRITZ!Box 7590", model, err)
+	}
+	var small uint8
+	if err := a.DecodeOutput(res, "NewUpTime", &small); err == nil {
+		t.Errorf("got %d, want an overflow error", small)
+	}
+	var enable bool
+	if err := a.DecodeOutput(res, "NewEnable", &enable); !errors.Is(err, ErrMissingOutput) {
+		t.Errorf("got %v, want ErrMissingOutput", err)
+	}
+}
+
+func TestOutputDecoder(t *testing.T) {
+	a := testAction()
+
+	var resp struct {
+		ModelName string
+		UpTime    uint32
+		Public    bool
+		Unknown   string
+	}
+	dec := a.NewOutputDecoder(Result{"ModelName": "FRITZ!Box 7590", "UpTime": uint64(70000), "X_AVM-DE_Public": true})
+	dec.Decode("NewModelName", &resp.ModelName)
+	dec.Decode("NewUpTime", &resp.UpTime)
+	dec.Decode("NewX_AVM-DE_Public", &resp.Public)
+	dec.Decode("NewUnknown", &resp.Unknown)
+	if err := dec.Err(); err != nil {
+		t.Fatalf("undeclared outputs should be left at their zero value, got %v", err)
+	}
+	if resp.ModelName != "FRITZ!Box 7590" || resp.UpTime != 70000 || !resp.Public || resp.Unknown != "" {
+		t.Errorf("got %+v", resp)
+	}
+
+	resp.Public = false
+	dec = a.NewOutputDecoder(Result{"ModelName": "FRITZ!Box 7590"})
+	dec.Decode("NewModelName", &resp.ModelName)
+	dec.Decode("NewUpTime", &resp.UpTime)
+	dec.Decode("NewX_AVM-DE_Public", &resp.Public)
+	if err := dec.Err(); !errors.Is(err, ErrMissingOutput) {
+		t.Errorf("got %v, want ErrMissingOutput for the declared outputs", err)
+	}
+	if got := dec.Missing(); !reflect.DeepEqual(got, []string{"NewUpTime", "NewX_AVM-DE_Public"}) {
+		t.Errorf("got missing outputs %v", got)
+	}
+
+	var model int32
+	var uptime uint8
+	dec = a.NewOutputDecoder(Result{"ModelName": "FRITZ!Box 7590", "UpTime": uint64(70000), "Enable": true})
+	dec.Decode("NewModelName", &model)
+	dec.Decode("NewUpTime", &uptime)
+	dec.Decode("NewEnable", &resp.Public)
+	errs, ok := dec.Err().(DecodeErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("got %v, want the errors of both outputs", dec.Err())
+	}
+	if !resp.Public {
+		t.Error("outputs after a failed one should still be decoded")
+	}
+}
+
+func TestDecodeOutputString64(t *testing.T) {
+	v := &StateVariable{Name: "X_AVM-DE_TotalBytesSent64", DataType: "string"}
+	arg := &Argument{Name: "NewX_AVM-DE_TotalBytesSent64", Direction: "out", RelatedStateVariable: v.Name, StateVariable: v}
+	a := &Action{Name: "GetTotalBytesSent", ArgumentMap: map[string]*Argument{arg.Name: arg}}
+
+	for _, tc := range []struct {
+		val  string
+		want uint64
+	}{
+		{val: "18446744073709551615", want: 18446744073709551615},
+		{val: "", want: 0},
+	} {
+		val, err := convertResult(tc.val, arg)
+		if err != nil {
+			t.Fatalf("convertResult(%q): %v", tc.val, err)
+		}
+
+		var got uint64
+		if err := a.DecodeOutput(Result{v.Name: val}, arg.Name, &got); err != nil {
+			t.Errorf("DecodeOutput(%q): %v", tc.val, err)
+		}
+		if got != tc.want {
+			t.Errorf("DecodeOutput(%q) = %d, want %d", tc.val, got, tc.want)
+		}
+	}
+}
+
+func TestConvertResultString64(t *testing.T) {
+	// Only the known counters are converted, other strings ending in 64 stay strings
+	v := &StateVariable{Name: "X_AVM-DE_PasswordBase64", DataType: "string"}
+	arg := &Argument{Name: "NewX_AVM-DE_PasswordBase64", Direction: "out", RelatedStateVariable: v.Name, StateVariable: v}
+	for _, val := range []string{"", "1234", "c2VjcmV0"} {
+		got, err := convertResult(val, arg)
+		if err != nil || got != val {
+			t.Errorf("convertResult(%q) = %#v, %v, want the string", val, got, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// digestChallenge holds the parameters of a WWW-Authenticate: Digest header
+type digestChallenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	Qop       string
+}
+
+// parseDigestChallenge parses a WWW-Authenticate header value
+func parseDigestChallenge(header string) (*digestChallenge, error) {
+	const prefix = "Digest "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, fmt.Errorf("unsupported authentication scheme: %q", header)
+	}
+
+	c := &digestChallenge{}
+	for _, param := range splitDigestParams(header[len(prefix):]) {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		val := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+
+		switch key {
+		case "realm":
+			c.Realm = val
+		case "nonce":
+			c.Nonce = val
+		case "opaque":
+			c.Opaque = val
+		case "algorithm":
+			c.Algorithm = val
+		case "qop":
+			c.Qop = val
+		}
+	}
+
+	if c.Nonce == "" {
+		return nil, errors.New("digest challenge without nonce")
+	}
+	return c, nil
+}
+
+// splitDigestParams splits the comma separated parameter list while keeping quoted commas
+func splitDigestParams(s string) []string {
+	var (
+		params []string
+		quoted bool
+		start  int
+	)
+	for i, r := range s {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				params = append(params, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(params, s[start:])
+}
+
+func (c *digestChallenge) hash(s string) string {
+	var h hash.Hash
+	switch strings.ToUpper(c.Algorithm) {
+	case "SHA-256", "SHA-256-SESS":
+		h = sha256.New()
+	default:
+		h = md5.New()
+	}
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// authorization computes the Authorization header for the request.
+// nc is the nonce count, which has to be increased for every request using the same nonce.
+func (c *digestChallenge) authorization(req *http.Request, username, password string, nc uint32) (string, error) {
+	cnonceBytes := make([]byte, 8)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", fmt.Errorf("could not create cnonce: %w", err)
+	}
+	return c.header(req.Method, req.URL.RequestURI(), username, password, nc, hex.EncodeToString(cnonceBytes)), nil
+}
+
+// header computes the Authorization header with the given client nonce
+func (c *digestChallenge) header(method, uri, username, password string, nc uint32, cnonce string) string {
+	ha1 := c.hash(fmt.Sprintf("%s:%s:%s", username, c.Realm, password))
+	if strings.HasSuffix(strings.ToUpper(c.Algorithm), "-SESS") {
+		ha1 = c.hash(fmt.Sprintf("%s:%s:%s", ha1, c.Nonce, cnonce))
+	}
+	ha2 := c.hash(fmt.Sprintf("%s:%s", method, uri))
+
+	var response string
+	qop := ""
+	if c.Qop != "" {
+		// auth-int is not supported, the FRITZ!Box only offers auth
+		qop = "auth"
+		response = c.hash(fmt.Sprintf("%s:%s:%08x:%s:%s:%s", ha1, c.Nonce, nc, cnonce, qop, ha2))
+	} else {
+		response = c.hash(fmt.Sprintf("%s:%s:%s", ha1, c.Nonce, ha2))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		username, c.Realm, c.Nonce, uri, response)
+	if c.Algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", c.Algorithm)
+	}
+	if c.Opaque != "" {
+		fmt.Fprintf(&b, `, opaque="%s"`, c.Opaque)
+	}
+	if qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%08x, cnonce="%s"`, qop, nc, cnonce)
+	}
+	return b.String()
+}
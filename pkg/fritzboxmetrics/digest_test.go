@@ -0,0 +1,144 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDigestParams(t *testing.T) {
+	got := splitDigestParams(`realm="a, b", qop="auth,auth-int", nonce="x",algorithm=MD5`)
+	want := []string{`realm="a, b"`, ` qop="auth,auth-int"`, ` nonce="x"`, `algorithm=MD5`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	// RFC 7616, section 3.9.1
+	c, err := parseDigestChallenge(`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, ` +
+		`nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`)
+	if err != nil {
+		t.Fatalf("parseDigestChallenge: %v", err)
+	}
+	want := &digestChallenge{
+		Realm:     "http-auth@example.org",
+		Nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+		Opaque:    "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+		Algorithm: "SHA-256",
+		Qop:       "auth, auth-int",
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+
+	for _, header := range []string{`Basic realm="fritz.box"`, `Digest realm="fritz.box"`} {
+		if _, err := parseDigestChallenge(header); err == nil {
+			t.Errorf("%s: got no error", header)
+		}
+	}
+}
+
+func TestDigestHeader(t *testing.T) {
+	rfc7616 := digestChallenge{
+		Realm:  "http-auth@example.org",
+		Nonce:  "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+		Opaque: "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+		Qop:    "auth, auth-int",
+	}
+	const rfc7616CNonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+
+	tests := []struct {
+		name      string
+		challenge digestChallenge
+		password  string
+		cnonce    string
+		want      string
+	}{
+		{
+			// RFC 2617, section 3.5
+			name: "RFC 2617 MD5",
+			challenge: digestChallenge{
+				Realm:  "testrealm@host.com",
+				Nonce:  "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+				Opaque: "5ccc069c403ebaf9f0171e9517f40e41",
+				Qop:    "auth,auth-int",
+			},
+			password: "Circle Of Life",
+			cnonce:   "0a4f113b",
+			want: `Digest username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", ` +
+				`uri="/dir/index.html", response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41", ` +
+				`qop=auth, nc=00000001, cnonce="0a4f113b"`,
+		},
+		{
+			// RFC 7616, section 3.9.1
+			name: "RFC 7616 MD5",
+			challenge: func() digestChallenge {
+				c := rfc7616
+				c.Algorithm = "MD5"
+				return c
+			}(),
+			password: "Circle of Life",
+			cnonce:   rfc7616CNonce,
+			want: `Digest username="Mufasa", realm="http-auth@example.org", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", ` +
+				`uri="/dir/index.html", response="8ca523f5e9506fed4657c9700eebdbec", algorithm=MD5, ` +
+				`opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", qop=auth, nc=00000001, cnonce="` + rfc7616CNonce + `"`,
+		},
+		{
+			name: "RFC 7616 SHA-256",
+			challenge: func() digestChallenge {
+				c := rfc7616
+				c.Algorithm = "SHA-256"
+				return c
+			}(),
+			password: "Circle of Life",
+			cnonce:   rfc7616CNonce,
+			want: `Digest username="Mufasa", realm="http-auth@example.org", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", ` +
+				`uri="/dir/index.html", response="753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1", algorithm=SHA-256, ` +
+				`opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", qop=auth, nc=00000001, cnonce="` + rfc7616CNonce + `"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.challenge.header("GET", "/dir/index.html", "Mufasa", tt.password, 1, tt.cnonce); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	c := &digestChallenge{Realm: "F!Box SOAP-Auth", Nonce: "0123456789ABCDEF", Qop: "auth"}
+	req := httptest.NewRequest("POST", "http://fritz.box:49000/upnp/control/deviceinfo?x=1", nil)
+
+	a, err := c.authorization(req, "user", "secret", 2)
+	if err != nil {
+		t.Fatalf("authorization: %v", err)
+	}
+	for _, want := range []string{`uri="/upnp/control/deviceinfo?x=1"`, "nc=00000002", `cnonce="`} {
+		if !strings.Contains(a, want) {
+			t.Errorf("%s lacks %s", a, want)
+		}
+	}
+
+	b, _ := c.authorization(req, "user", "secret", 2)
+	if a == b {
+		t.Error("the client nonce is not random")
+	}
+}
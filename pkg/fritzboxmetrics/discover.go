@@ -0,0 +1,170 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SSDPAddr is the multicast address used for SSDP discovery
+const SSDPAddr = "239.255.255.250:1900"
+
+// discoverWait is how long Discover listens for responses if ctx has no deadline
+const discoverWait = 3 * time.Second
+
+// DefaultSearchTargets are the device types Discover searches for
+var DefaultSearchTargets = []string{
+	"urn:dslforum-org:device:InternetGatewayDevice:1",
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:1",
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:2",
+}
+
+// DiscoveredDevice describes a device which answered an SSDP search
+type DiscoveredDevice struct {
+	Host     string // Host of the LOCATION URL, usable as device for LoadServices
+	Port     uint16 // Port of the LOCATION URL
+	Location string // URL of the device description
+	ST       string // Search target the device answered to
+	USN      string // Unique service name
+	Server   string // SERVER header, e.g. FRITZ!Box 7590 UPnP/1.0 AVM FRITZ!Box 7590 154.07.29
+}
+
+// Discover searches the LAN for FRITZ!Box devices using SSDP M-SEARCH.
+// It collects responses until ctx is done or, if ctx has no deadline, for 3 seconds.
+func Discover(ctx context.Context) ([]DiscoveredDevice, error) {
+	return DiscoverAt(ctx, SSDPAddr, DefaultSearchTargets)
+}
+
+// DiscoverAt sends the M-SEARCH requests for the given search targets to addr.
+func DiscoverAt(ctx context.Context, addr string, searchTargets []string) ([]DiscoveredDevice, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s: %w", addr, err)
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(discoverWait)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("could not set deadline: %w", err)
+	}
+
+	// Unblock the read loop when ctx is cancelled
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	for _, st := range searchTargets {
+		msg := fmt.Sprintf("M-SEARCH * HTTP/1.1\r\n"+
+			"HOST: %s\r\n"+
+			"MAN: \"ssdp:discover\"\r\n"+
+			"MX: 2\r\n"+
+			"ST: %s\r\n\r\n", SSDPAddr, st)
+		if _, err := conn.WriteToUDP([]byte(msg), raddr); err != nil {
+			return nil, fmt.Errorf("could not send M-SEARCH: %w", err)
+		}
+	}
+
+	targets := make(map[string]bool)
+	for _, st := range searchTargets {
+		targets[st] = true
+	}
+
+	var devices []DiscoveredDevice
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return devices, nil
+			}
+			return devices, fmt.Errorf("could not read SSDP response: %w", err)
+		}
+
+		device, err := parseSSDPResponse(buf[:n])
+		if err != nil {
+			// Ignore garbage from other devices
+			continue
+		}
+		if !targets[device.ST] && !targets["ssdp:all"] {
+			// Answer to a search of another control point
+			continue
+		}
+		key := device.USN + "|" + device.ST
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		devices = append(devices, device)
+	}
+}
+
+func parseSSDPResponse(data []byte) (DiscoveredDevice, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return DiscoveredDevice{}, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return DiscoveredDevice{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	location := resp.Header.Get("Location")
+	u, err := url.Parse(location)
+	if err != nil || u.Hostname() == "" {
+		return DiscoveredDevice{}, fmt.Errorf("invalid LOCATION: %q", location)
+	}
+
+	var port uint64 = 80
+	if p := u.Port(); p != "" {
+		if port, err = strconv.ParseUint(p, 10, 16); err != nil {
+			return DiscoveredDevice{}, fmt.Errorf("invalid port in LOCATION: %q", location)
+		}
+	}
+
+	return DiscoveredDevice{
+		Host:     u.Hostname(),
+		Port:     uint16(port),
+		Location: location,
+		ST:       resp.Header.Get("St"),
+		USN:      resp.Header.Get("Usn"),
+		Server:   resp.Header.Get("Server"),
+	}, nil
+}
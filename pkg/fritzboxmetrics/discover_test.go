@@ -0,0 +1,159 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const ssdpResponse = "HTTP/1.1 200 OK\r\n" +
+	"LOCATION: http://192.168.178.1:49000/tr64desc.xml\r\n" +
+	"SERVER: FRITZ!Box 7590 UPnP/1.0 AVM FRITZ!Box 7590 154.07.29\r\n" +
+	"CACHE-CONTROL: max-age=1800\r\n" +
+	"EXT:\r\n" +
+	"ST: %s\r\n" +
+	"USN: uuid:739f2409-bccb-40e7-8e6c-3431C4000001::%s\r\n\r\n"
+
+// ssdpResponder answers every M-SEARCH on a local UDP socket like a FRITZ!Box.
+// Every answer is sent twice, followed by an answer for a target nobody
+// searched for and a datagram which is no HTTP response.
+func ssdpResponder(t *testing.T) string {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(buf[:n])))
+			if err != nil || req.Method != "M-SEARCH" {
+				continue
+			}
+			st := req.Header.Get("St")
+			for _, msg := range []string{
+				fmt.Sprintf(ssdpResponse, st, st),
+				fmt.Sprintf(ssdpResponse, st, st),
+				fmt.Sprintf(ssdpResponse, "urn:schemas-upnp-org:service:WANIPConnection:1", "urn:schemas-upnp-org:service:WANIPConnection:1"),
+				"garbage",
+			} {
+				conn.WriteToUDP([]byte(msg), addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDiscoverAt(t *testing.T) {
+	addr := ssdpResponder(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	targets := []string{
+		"urn:dslforum-org:device:InternetGatewayDevice:1",
+		"urn:schemas-upnp-org:device:InternetGatewayDevice:1",
+	}
+	devices, err := DiscoverAt(ctx, addr, targets)
+	if err != nil {
+		t.Fatalf("DiscoverAt: %v", err)
+	}
+	if len(devices) != len(targets) {
+		t.Fatalf("got %d devices, want one per search target: %+v", len(devices), devices)
+	}
+
+	found := make(map[string]DiscoveredDevice)
+	for _, d := range devices {
+		found[d.ST] = d
+	}
+	for _, st := range targets {
+		d, ok := found[st]
+		if !ok {
+			t.Errorf("no device found for %s", st)
+			continue
+		}
+		want := DiscoveredDevice{
+			Host:     "192.168.178.1",
+			Port:     49000,
+			Location: "http://192.168.178.1:49000/tr64desc.xml",
+			ST:       st,
+			USN:      "uuid:739f2409-bccb-40e7-8e6c-3431C4000001::" + st,
+			Server:   "FRITZ!Box 7590 UPnP/1.0 AVM FRITZ!Box 7590 154.07.29",
+		}
+		if d != want {
+			t.Errorf("got %+v, want %+v", d, want)
+		}
+	}
+}
+
+func TestDiscoverAtCancel(t *testing.T) {
+	addr := ssdpResponder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := DiscoverAt(ctx, addr, DefaultSearchTargets); err != nil {
+		t.Fatalf("DiscoverAt: %v", err)
+	}
+	if d := time.Since(start); d >= discoverWait {
+		t.Errorf("DiscoverAt returned after %v, want it to stop when ctx is cancelled", d)
+	}
+}
+
+func TestParseSSDPResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		port uint16
+		err  bool
+	}{
+		{name: "default port", data: "HTTP/1.1 200 OK\r\nLOCATION: http://fritz.box/tr64desc.xml\r\n\r\n", port: 80},
+		{name: "explicit port", data: "HTTP/1.1 200 OK\r\nLOCATION: http://fritz.box:49000/tr64desc.xml\r\n\r\n", port: 49000},
+		{name: "no location", data: "HTTP/1.1 200 OK\r\nST: upnp:rootdevice\r\n\r\n", err: true},
+		{name: "invalid port", data: "HTTP/1.1 200 OK\r\nLOCATION: http://fritz.box:99999/\r\n\r\n", err: true},
+		{name: "error status", data: "HTTP/1.1 404 Not Found\r\nLOCATION: http://fritz.box/\r\n\r\n", err: true},
+		{name: "no response", data: "NOTIFY * HTTP/1.1\r\n\r\n", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := parseSSDPResponse([]byte(tc.data))
+			if tc.err {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSSDPResponse: %v", err)
+			}
+			if d.Host != "fritz.box" || d.Port != tc.port {
+				t.Errorf("got %s:%d, want fritz.box:%d", d.Host, d.Port, tc.port)
+			}
+		})
+	}
+}
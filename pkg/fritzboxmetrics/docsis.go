@@ -0,0 +1,123 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// dataPath is the page data interface of the web interface
+const dataPath = "/data.lua"
+
+// DOCSISValue is a value of the DOCSIS channel tables. Depending on the
+// firmware FRITZ!OS sends it as JSON string or number.
+type DOCSISValue string
+
+// UnmarshalJSON accepts strings, numbers and null
+func (v *DOCSISValue) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = DOCSISValue(strings.TrimSpace(s))
+		return nil
+	}
+	*v = DOCSISValue(data)
+	return nil
+}
+
+// Float64 parses the value. Ranges like "751 - 861" return their first number.
+func (v DOCSISValue) Float64() (float64, bool) {
+	fields := strings.Fields(string(v))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// DOCSISChannel is a row of the channel tables of the cable information page.
+// Values a channel type doesn't have are empty.
+type DOCSISChannel struct {
+	ChannelID  DOCSISValue `json:"channelID"`
+	Channel    DOCSISValue `json:"channel"`
+	Frequency  DOCSISValue `json:"frequency"`  // MHz
+	PowerLevel DOCSISValue `json:"powerLevel"` // dBmV
+	Type       DOCSISValue `json:"type"`       // Modulation on firmware without the modulation field
+	Modulation DOCSISValue `json:"modulation"`
+	Multiplex  DOCSISValue `json:"multiplex"` // Upstream DOCSIS 3.0 only
+	MSE        DOCSISValue `json:"mse"`       // dB, downstream DOCSIS 3.0 only
+	MER        DOCSISValue `json:"mer"`       // dB, downstream DOCSIS 3.1 only
+	Latency    DOCSISValue `json:"latency"`   // ms
+
+	CorrectedErrors     DOCSISValue `json:"corrErrors"`
+	UncorrectableErrors DOCSISValue `json:"nonCorrErrors"`
+}
+
+// ModulationName returns the modulation of the channel, e.g. 256QAM
+func (c *DOCSISChannel) ModulationName() string {
+	if c.Modulation != "" {
+		return string(c.Modulation)
+	}
+	return string(c.Type)
+}
+
+// DOCSISChannels are the channels of one direction
+type DOCSISChannels struct {
+	DOCSIS30 []DOCSISChannel `json:"docsis30"`
+	DOCSIS31 []DOCSISChannel `json:"docsis31"`
+}
+
+// DOCSISInfo are the channel tables of a cable model
+type DOCSISInfo struct {
+	Downstream DOCSISChannels `json:"channelDs"`
+	Upstream   DOCSISChannels `json:"channelUs"`
+}
+
+// DOCSISInfo reads the DOCSIS channel tables from the page docInfo
+// (Internet > Cable Information > Channels) of the web interface
+func (s *Session) DOCSISInfo(ctx context.Context) (*DOCSISInfo, error) {
+	data, err := s.Post(ctx, dataPath, url.Values{
+		"xhr":         {"1"},
+		"lang":        {"en"},
+		"page":        {"docInfo"},
+		"xhrId":       {"all"},
+		"no_sidrenew": {""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get DOCSIS information: %w", err)
+	}
+
+	var page struct {
+		Data DOCSISInfo `json:"data"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("could not decode DOCSIS information: %w", err)
+	}
+	return &page.Data, nil
+}
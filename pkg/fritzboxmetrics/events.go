@@ -0,0 +1,419 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSubscriptionTimeout is requested from the device for every subscription
+	defaultSubscriptionTimeout = 30 * time.Minute
+	// subscriptionRetryTime is the wait time after a failed renewal
+	subscriptionRetryTime = 1 * time.Minute
+	// pendingNotifyWait is how long a NOTIFY for an unknown SID waits for
+	// pending SUBSCRIBE requests, whose response it may have overtaken
+	pendingNotifyWait = 5 * time.Second
+)
+
+// Event is a NOTIFY message with the changed state variables of a service
+type Event struct {
+	Service   *Service
+	SID       string
+	Seq       uint32
+	Variables map[string]string // New values indexed by the state variable name
+}
+
+// SubscriptionOptions configure a SubscriptionManager
+type SubscriptionOptions struct {
+	// ListenAddr is the address of the callback HTTP server, e.g. :9134
+	ListenAddr string
+	// CallbackURL is the URL the device sends NOTIFY messages to.
+	// If empty, it is derived from the local address used to reach the device.
+	CallbackURL string
+}
+
+// SubscriptionManager subscribes to GENA events of services and delivers them on a channel.
+// Subscriptions are renewed before they time out.
+type SubscriptionManager struct {
+	client      *Client
+	callbackURL string
+	listener    net.Listener
+	server      *http.Server
+	events      chan Event
+	done        chan struct{}
+
+	mu      sync.Mutex               // protects the fields below
+	subs    map[string]*subscription // indexed by SID
+	pending int                      // SUBSCRIBE requests without response
+	changed chan struct{}            // closed when a pending request is done
+}
+
+type subscription struct {
+	service  *Service
+	eventURL string
+	sid      string
+	timeout  time.Duration
+	cancel   context.CancelFunc
+}
+
+// NewSubscriptionManager starts the callback HTTP server for NOTIFY messages
+func NewSubscriptionManager(client *Client, opts SubscriptionOptions) (*SubscriptionManager, error) {
+	listener, err := net.Listen("tcp", opts.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for events: %w", err)
+	}
+
+	m := &SubscriptionManager{
+		client:      client,
+		callbackURL: opts.CallbackURL,
+		listener:    listener,
+		events:      make(chan Event),
+		done:        make(chan struct{}),
+		subs:        make(map[string]*subscription),
+		changed:     make(chan struct{}),
+	}
+	m.server = &http.Server{Handler: http.HandlerFunc(m.handleNotify)}
+
+	go func() {
+		if err := m.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("event callback server failed: %v", err)
+		}
+	}()
+	return m, nil
+}
+
+// Events returns the channel the events of all subscriptions are delivered on.
+// The channel has to be drained, otherwise the device can't deliver further events.
+func (m *SubscriptionManager) Events() <-chan Event {
+	return m.events
+}
+
+// Subscribe subscribes to the events of the service.
+// If the event URL of the service is already subscribed, the subscription is kept
+// and its events are reported for the given service from now on.
+func (m *SubscriptionManager) Subscribe(ctx context.Context, s *Service) error {
+	if s.EventSubURL == "" {
+		return fmt.Errorf("service %s has no event URL", s.ServiceType)
+	}
+	eventURL := s.Device.root.BaseURL + s.EventSubURL
+
+	m.mu.Lock()
+	for _, sub := range m.subs {
+		if sub.eventURL == eventURL {
+			sub.service = s
+			m.mu.Unlock()
+			return nil
+		}
+	}
+	m.mu.Unlock()
+
+	callbackURL, err := m.callbackFor(s.Device.root.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	renewCtx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{service: s, eventURL: eventURL, cancel: cancel}
+
+	m.beginPending()
+	sub.sid, sub.timeout, err = m.subscribe(ctx, eventURL, callbackURL)
+	m.endPending(func() {
+		if err == nil {
+			m.subs[sub.sid] = sub
+		}
+	})
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go m.renewLoop(renewCtx, sub, callbackURL)
+	return nil
+}
+
+// beginPending marks a SUBSCRIBE request as sent. The device may send the
+// initial NOTIFY before the SID is known from the response.
+func (m *SubscriptionManager) beginPending() {
+	m.mu.Lock()
+	m.pending++
+	m.mu.Unlock()
+}
+
+// endPending updates the subscriptions with the result of a pending request
+// and wakes up the NOTIFY handlers waiting for it
+func (m *SubscriptionManager) endPending(update func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	update()
+	m.pending--
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// lookup returns the service subscribed with the SID. An unknown SID is looked
+// up again as long as SUBSCRIBE requests are pending, at most for pendingNotifyWait.
+func (m *SubscriptionManager) lookup(ctx context.Context, sid string) (*Service, bool) {
+	timeout := time.NewTimer(pendingNotifyWait)
+	defer timeout.Stop()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if sub, ok := m.subs[sid]; ok {
+			return sub.service, true
+		}
+		if m.pending == 0 {
+			return nil, false
+		}
+
+		changed := m.changed
+		m.mu.Unlock()
+		select {
+		case <-changed:
+		case <-timeout.C:
+			m.mu.Lock()
+			return nil, false
+		case <-ctx.Done():
+			m.mu.Lock()
+			return nil, false
+		case <-m.done:
+			m.mu.Lock()
+			return nil, false
+		}
+		m.mu.Lock()
+	}
+}
+
+// Close cancels all subscriptions, stops the callback server and closes the events channel.
+// If ctx is done before all NOTIFY handlers returned, the events channel is left open.
+func (m *SubscriptionManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	subs := m.subs
+	m.subs = make(map[string]*subscription)
+	m.mu.Unlock()
+
+	for sid, sub := range subs {
+		sub.cancel()
+		if _, _, err := m.request(ctx, "UNSUBSCRIBE", sub.eventURL, map[string]string{"SID": sid}); err != nil {
+			log.Printf("could not unsubscribe from %s: %v", sub.eventURL, err)
+		}
+	}
+
+	close(m.done)
+	if err := m.server.Shutdown(ctx); err != nil {
+		// Handlers may still be running. They return because done is closed,
+		// but must not send on a closed channel.
+		return err
+	}
+	// No handler is running anymore
+	close(m.events)
+	return nil
+}
+
+// callbackFor returns the callback URL the device at baseURL can reach
+func (m *SubscriptionManager) callbackFor(baseURL string) (string, error) {
+	if m.callbackURL != "" {
+		return m.callbackURL, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("could not parse base URL: %w", err)
+	}
+	// No packets are sent, this only determines the route to the device
+	conn, err := net.Dial("udp", u.Host)
+	if err != nil {
+		return "", fmt.Errorf("could not determine local address: %w", err)
+	}
+	defer conn.Close()
+
+	localIP := conn.LocalAddr().(*net.UDPAddr).IP
+	_, port, err := net.SplitHostPort(m.listener.Addr().String())
+	if err != nil {
+		return "", fmt.Errorf("could not determine callback port: %w", err)
+	}
+	return fmt.Sprintf("http://%s/", net.JoinHostPort(localIP.String(), port)), nil
+}
+
+// subscribe creates a new subscription and returns its SID and timeout
+func (m *SubscriptionManager) subscribe(ctx context.Context, eventURL, callbackURL string) (string, time.Duration, error) {
+	return m.request(ctx, "SUBSCRIBE", eventURL, map[string]string{
+		"CALLBACK": "<" + callbackURL + ">",
+		"NT":       "upnp:event",
+		"TIMEOUT":  formatGENATimeout(defaultSubscriptionTimeout),
+	})
+}
+
+// renew extends the subscription with the given SID
+func (m *SubscriptionManager) renew(ctx context.Context, eventURL, sid string) (string, time.Duration, error) {
+	return m.request(ctx, "SUBSCRIBE", eventURL, map[string]string{
+		"SID":     sid,
+		"TIMEOUT": formatGENATimeout(defaultSubscriptionTimeout),
+	})
+}
+
+func (m *SubscriptionManager) renewLoop(ctx context.Context, sub *subscription, callbackURL string) {
+	m.mu.Lock()
+	sid, timeout := sub.sid, sub.timeout
+	m.mu.Unlock()
+
+	wait := timeout * 4 / 5
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		newSID, newTimeout, err := m.renew(ctx, sub.eventURL, sid)
+		if err != nil {
+			// The device may have forgotten the subscription, e.g. after a reboot
+			log.Printf("could not renew subscription %s, subscribing again: %v", sid, err)
+			m.beginPending()
+			newSID, newTimeout, err = m.subscribe(ctx, sub.eventURL, callbackURL)
+			m.endPending(func() {
+				if err == nil {
+					m.replace(sub, sid, newSID, newTimeout)
+				}
+			})
+		} else {
+			m.mu.Lock()
+			m.replace(sub, sid, newSID, newTimeout)
+			m.mu.Unlock()
+		}
+		if err != nil {
+			log.Printf("could not subscribe to %s: %v", sub.eventURL, err)
+			wait = subscriptionRetryTime
+			continue
+		}
+
+		sid = newSID
+		wait = newTimeout * 4 / 5
+	}
+}
+
+// replace moves the subscription to its new SID unless it was cancelled.
+// m.mu has to be held.
+func (m *SubscriptionManager) replace(sub *subscription, oldSID, newSID string, timeout time.Duration) {
+	if _, ok := m.subs[oldSID]; ok {
+		delete(m.subs, oldSID)
+		sub.sid, sub.timeout = newSID, timeout
+		m.subs[newSID] = sub
+	}
+}
+
+// request sends a GENA request and returns SID and timeout of the response, if any
+func (m *SubscriptionManager) request(ctx context.Context, method, eventURL string, header map[string]string) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, eventURL, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not create new request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = []string{v}
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if method == "UNSUBSCRIBE" {
+		return "", 0, nil
+	}
+
+	sid := resp.Header.Get("SID")
+	if sid == "" {
+		return "", 0, errors.New("response without SID")
+	}
+	return sid, parseGENATimeout(resp.Header.Get("TIMEOUT")), nil
+}
+
+func (m *SubscriptionManager) handleNotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "NOTIFY" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sid := r.Header.Get("SID")
+	service, ok := m.lookup(r.Context(), sid)
+	if !ok {
+		http.Error(w, "unknown subscription", http.StatusPreconditionFailed)
+		return
+	}
+
+	var props propertySet
+	if err := xml.NewDecoder(r.Body).Decode(&props); err != nil {
+		http.Error(w, "invalid property set", http.StatusBadRequest)
+		return
+	}
+
+	seq, _ := strconv.ParseUint(r.Header.Get("SEQ"), 10, 32)
+	ev := Event{
+		Service:   service,
+		SID:       sid,
+		Seq:       uint32(seq),
+		Variables: make(map[string]string),
+	}
+	for _, p := range props.Properties {
+		for _, v := range p.Variables {
+			ev.Variables[v.XMLName.Local] = v.Value
+		}
+	}
+
+	select {
+	case m.events <- ev:
+	case <-m.done:
+	case <-r.Context().Done():
+	}
+}
+
+type propertySet struct {
+	Properties []struct {
+		Variables []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"property"`
+}
+
+func formatGENATimeout(d time.Duration) string {
+	return fmt.Sprintf("Second-%d", int(d.Seconds()))
+}
+
+// parseGENATimeout parses the TIMEOUT header, e.g. Second-1800
+func parseGENATimeout(header string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimPrefix(header, "Second-"))
+	if err != nil || secs <= 0 {
+		// "infinite" or invalid, renew with the requested timeout anyway
+		return defaultSubscriptionTimeout
+	}
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,124 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPropertySet = `<?xml version="1.0"?>
+<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0">
+<e:property><ConnectionStatus>Connected</ConnectionStatus></e:property>
+<e:property><ExternalIPAddress>192.0.2.1</ExternalIPAddress></e:property>
+</e:propertyset>`
+
+// genaDevice answers SUBSCRIBE like a device which sends the initial NOTIFY
+// before its response to the SUBSCRIBE arrives
+func genaDevice(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "SUBSCRIBE":
+			callback := strings.Trim(r.Header.Get("CALLBACK"), "<>")
+			go func() {
+				req, _ := http.NewRequest("NOTIFY", callback, strings.NewReader(testPropertySet))
+				req.Header.Set("SID", "uuid:sub-1")
+				req.Header.Set("SEQ", "0")
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Errorf("could not send NOTIFY: %v", err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf("NOTIFY answered with %d", resp.StatusCode)
+				}
+			}()
+			time.Sleep(100 * time.Millisecond)
+			w.Header().Set("SID", "uuid:sub-1")
+			w.Header().Set("TIMEOUT", "Second-1800")
+		case "UNSUBSCRIBE":
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSubscribeInitialEvent(t *testing.T) {
+	srv := genaDevice(t)
+	service := &Service{
+		ServiceType: "urn:schemas-upnp-org:service:WANIPConnection:1",
+		EventSubURL: "/igdupnp/control/wanipconnection1",
+		Device:      &Device{root: &Root{BaseURL: srv.URL}},
+	}
+
+	m, err := NewSubscriptionManager(NewClient(ClientOptions{}), SubscriptionOptions{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("NewSubscriptionManager: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Subscribe(ctx, service); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	select {
+	case ev := <-m.Events():
+		if ev.Service != service || ev.SID != "uuid:sub-1" || ev.Seq != 0 {
+			t.Errorf("got event %+v for SID %s and SEQ 0", ev, "uuid:sub-1")
+		}
+		if got := ev.Variables["ConnectionStatus"]; got != "Connected" {
+			t.Errorf("ConnectionStatus = %q, want Connected", got)
+		}
+		if got := ev.Variables["ExternalIPAddress"]; got != "192.0.2.1" {
+			t.Errorf("ExternalIPAddress = %q, want 192.0.2.1", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("initial event was not delivered")
+	}
+
+	if err := m.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, ok := <-m.Events(); ok {
+		t.Error("events channel is still open after Close")
+	}
+}
+
+func TestNotifyUnknownSID(t *testing.T) {
+	m, err := NewSubscriptionManager(NewClient(ClientOptions{}), SubscriptionOptions{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("NewSubscriptionManager: %v", err)
+	}
+	defer m.Close(context.Background())
+
+	req, _ := http.NewRequest("NOTIFY", "http://"+m.listener.Addr().String()+"/", strings.NewReader(testPropertySet))
+	req.Header.Set("SID", "uuid:unknown")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("could not send NOTIFY: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("NOTIFY answered with %d, want %d", resp.StatusCode, http.StatusPreconditionFailed)
+	}
+}
@@ -0,0 +1,100 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// Well-known UPnP error codes, usable with errors.Is on a *SOAPFault
+var (
+	ErrInvalidAction                = errors.New("invalid action")                  // 401
+	ErrInvalidArgs                  = errors.New("invalid args")                    // 402
+	ErrActionFailed                 = errors.New("action failed")                   // 501
+	ErrArgumentValueInvalid         = errors.New("argument value invalid")          // 600
+	ErrArgumentValueOutOfRange      = errors.New("argument value out of range")     // 601
+	ErrOptionalActionNotImplemented = errors.New("optional action not implemented") // 602
+	ErrOutOfMemory                  = errors.New("out of memory")                   // 603
+	ErrHumanInterventionRequired    = errors.New("human intervention required")     // 604
+	ErrStringArgumentTooLong        = errors.New("string argument too long")        // 605
+	ErrActionNotAuthorized          = errors.New("action not authorized")           // 606
+	ErrSpecifiedArrayIndexInvalid   = errors.New("specified array index invalid")   // 713
+	ErrNoSuchEntryInArray           = errors.New("no such entry in array")          // 714
+	ErrInternalError                = errors.New("internal error")                  // 820
+)
+
+var upnpErrors = map[int]error{
+	401: ErrInvalidAction,
+	402: ErrInvalidArgs,
+	501: ErrActionFailed,
+	600: ErrArgumentValueInvalid,
+	601: ErrArgumentValueOutOfRange,
+	602: ErrOptionalActionNotImplemented,
+	603: ErrOutOfMemory,
+	604: ErrHumanInterventionRequired,
+	605: ErrStringArgumentTooLong,
+	606: ErrActionNotAuthorized,
+	713: ErrSpecifiedArrayIndexInvalid,
+	714: ErrNoSuchEntryInArray,
+	820: ErrInternalError,
+}
+
+// SOAPFault is returned by Call if the device answers with a SOAP fault
+type SOAPFault struct {
+	FaultCode        string // e.g. s:Client
+	FaultString      string // e.g. UPnPError
+	ErrorCode        int    // UPnP error code, e.g. 606
+	ErrorDescription string // e.g. Action not authorized
+}
+
+func (f *SOAPFault) Error() string {
+	if f.ErrorCode == 0 {
+		return fmt.Sprintf("SOAP fault %s: %s", f.FaultCode, f.FaultString)
+	}
+	return fmt.Sprintf("UPnP error %d: %s", f.ErrorCode, f.ErrorDescription)
+}
+
+// Is reports whether the fault matches one of the well-known UPnP errors
+func (f *SOAPFault) Is(target error) bool {
+	err, ok := upnpErrors[f.ErrorCode]
+	return ok && err == target
+}
+
+type soapFaultEnvelope struct {
+	Fault *struct {
+		FaultCode   string `xml:"faultcode"`
+		FaultString string `xml:"faultstring"`
+		UPnPError   struct {
+			ErrorCode        int    `xml:"errorCode"`
+			ErrorDescription string `xml:"errorDescription"`
+		} `xml:"detail>UPnPError"`
+	} `xml:"Body>Fault"`
+}
+
+// parseSOAPFault returns the fault contained in a SOAP response or nil if there is none
+func parseSOAPFault(data []byte) *SOAPFault {
+	var env soapFaultEnvelope
+	if err := xml.Unmarshal(data, &env); err != nil || env.Fault == nil {
+		return nil
+	}
+	return &SOAPFault{
+		FaultCode:        env.Fault.FaultCode,
+		FaultString:      env.Fault.FaultString,
+		ErrorCode:        env.Fault.UPnPError.ErrorCode,
+		ErrorDescription: env.Fault.UPnPError.ErrorDescription,
+	}
+}
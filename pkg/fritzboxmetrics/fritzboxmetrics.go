@@ -17,20 +17,29 @@ package fritzboxmetrics
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	dac "github.com/123Haynes/go-http-digest-auth-client"
+	"unicode/utf8"
 )
 
 const (
 	RFC3339_WITHOUT_TZ = "2006-01-02T15:04:05"
+
+	dateLayout   = "2006-01-02"
+	timeLayout   = "15:04:05"
+	timeTZLayout = "15:04:05Z07:00"
 )
 
 // curl http://fritz.box:49000/igddesc.xml
@@ -45,13 +54,38 @@ const textXML = `text/xml; charset="utf-8"`
 // ErrInvalidSOAPResponse will be thrown if we've got an invalid SOAP response
 var ErrInvalidSOAPResponse = errors.New("invalid SOAP response")
 
+// ErrInvalidArgument will be thrown if the input arguments of a call don't match the action
+var ErrInvalidArgument = errors.New("invalid argument")
+
 // Root of the UPNP tree
 type Root struct {
-	BaseURL  string
-	Username string
-	Password string
-	Device   Device              `xml:"device"`
-	Services map[string]*Service // Map of all services indexed by .ServiceType
+	BaseURL       string
+	SecureBaseURL string                  // TLS endpoint for SOAP calls, BaseURL is used if empty
+	Device        Device                  `xml:"device"`
+	Services      map[string]*Service     // Map of the first service of each .ServiceType, see Root.Service
+	Instances     map[ServiceKey]*Service // Map of all service instances, see ServicesByType
+	SystemVersion SystemVersion           `xml:"systemVersion"` // Only present in tr64desc.xml
+
+	// Deprecated: the credentials and the timeout are options of the Client
+	// which sends the calls, see ClientOptions. They are still filled in by
+	// LoadServices, but changing them has no effect.
+	Username string        `xml:"-"`
+	Password string        `xml:"-"`
+	Timeout  time.Duration `xml:"-"`
+
+	client *Client
+	fetch  fetchFunc
+	bundle *Bundle
+}
+
+// SystemVersion is the firmware version advertised in tr64desc.xml
+type SystemVersion struct {
+	HW          string `xml:"HW"`
+	Major       string `xml:"Major"`
+	Minor       string `xml:"Minor"`
+	Patch       string `xml:"Patch"`
+	Buildnumber string `xml:"Buildnumber"`
+	Display     string `xml:"Display"` // e.g. 154.07.29
 }
 
 // Device represents an UPNP device
@@ -77,6 +111,7 @@ type Device struct {
 // Service represents an UPnP Service
 type Service struct {
 	Device *Device
+	Source ServiceSource // The description tree the service was loaded from
 
 	ServiceType string `xml:"serviceType"`
 	ServiceID   string `xml:"serviceId"`
@@ -113,6 +148,17 @@ func (a *Action) IsGetOnly() bool {
 	return len(a.Arguments) > 0
 }
 
+// Output returns the value of the output argument with the given name (e.g. NewSecurityPort)
+// from a result of the action.
+func (a *Action) Output(res Result, name string) (interface{}, bool) {
+	arg, ok := a.ArgumentMap[name]
+	if !ok || arg.StateVariable == nil {
+		return nil, false
+	}
+	val, ok := res[arg.StateVariable.Name]
+	return val, ok
+}
+
 // An Argument to an action
 type Argument struct {
 	Name                 string `xml:"name"`
@@ -126,61 +172,81 @@ type StateVariable struct {
 	Name         string `xml:"name"`
 	DataType     string `xml:"dataType"`
 	DefaultValue string `xml:"defaultValue"`
+	SendEvents   string `xml:"sendEvents,attr"` // yes if changes are sent as events
+
+	AllowedValues []string           `xml:"allowedValueList>allowedValue"` // Enumeration of all valid values, if any
+	AllowedRange  *AllowedValueRange `xml:"allowedValueRange"`             // Range of valid numeric values, if any
+}
+
+// AllowedValueRange restricts the values of a numeric state variable.
+// The bounds are kept as in the SCPD, an empty bound is not restricted.
+type AllowedValueRange struct {
+	Minimum string `xml:"minimum"`
+	Maximum string `xml:"maximum"`
+	Step    string `xml:"step"`
+}
+
+// IsEvented returns if changes of the variable are sent as events
+func (v *StateVariable) IsEvented() bool {
+	return v.SendEvents == "yes"
 }
 
 // Result are all output argements of the Call():
 // The map is indexed by the name of the state variable.
-// The type of the value is string, uint64 or bool depending of the DataType of the variable.
+// The type of the value depends on the DataType of the variable: string for string, char,
+// uuid and uri, bool for boolean, uint64 for ui1 to ui8, int64 for i1 to i8 and int, float64
+// for r4, r8, number, float and fixed.14.4, []byte for bin.base64 and bin.hex and time.Time for
+// date, time and dateTime. AVM's 64 bit counters (string variables ending in 64) are uint64.
+// Values of unknown types are returned as string.
 type Result map[string]interface{}
 
 // load the whole tree
-func (r *Root) load() error {
-	response, err := http.Get(fmt.Sprintf("%s/igddesc.xml", r.BaseURL))
+func (r *Root) load(ctx context.Context) error {
+	data, err := r.fetch(ctx, "/igddesc.xml")
 	if err != nil {
 		return fmt.Errorf("could not get igddesc.xml: %w", err)
 	}
 
-	dec := xml.NewDecoder(response.Body)
-
-	if err = dec.Decode(r); err != nil {
+	if err = xml.Unmarshal(data, r); err != nil {
 		return fmt.Errorf("could not decode XML: %w", err)
 	}
 
 	r.Services = make(map[string]*Service)
-	return r.Device.fillServices(r)
+	r.Instances = make(map[ServiceKey]*Service)
+	return r.Device.fillServices(ctx, r, SourceIGD)
 }
 
-func (r *Root) loadTr64() error {
-	igddesc, err := http.Get(fmt.Sprintf("%s/tr64desc.xml", r.BaseURL))
+func (r *Root) loadTr64(ctx context.Context) error {
+	data, err := r.fetch(ctx, "/tr64desc.xml")
 	if err != nil {
 		return fmt.Errorf("could not fetch tr64desc.xml: %w", err)
 	}
 
-	dec := xml.NewDecoder(igddesc.Body)
-	if err = dec.Decode(r); err != nil {
+	if err = xml.Unmarshal(data, r); err != nil {
 		return fmt.Errorf("could not decode XML: %w", err)
 	}
 
 	r.Services = make(map[string]*Service)
-	return r.Device.fillServices(r)
+	r.Instances = make(map[ServiceKey]*Service)
+	return r.Device.fillServices(ctx, r, SourceTR64)
 }
 
 // load all service descriptions
-func (d *Device) fillServices(r *Root) error {
+func (d *Device) fillServices(ctx context.Context, r *Root, source ServiceSource) error {
 	d.root = r
 
 	for _, s := range d.Services {
 		s.Device = d
+		s.Source = source
 
-		response, err := http.Get(r.BaseURL + s.SCPDURL)
+		data, err := r.fetch(ctx, s.SCPDURL)
 		if err != nil {
 			return fmt.Errorf("could not get service descriptions: %w", err)
 		}
 
 		var scpd scpdRoot
 
-		dec := xml.NewDecoder(response.Body)
-		if err = dec.Decode(&scpd); err != nil {
+		if err = xml.Unmarshal(data, &scpd); err != nil {
 			return fmt.Errorf("could not decode xml: %w", err)
 		}
 
@@ -205,32 +271,60 @@ func (d *Device) fillServices(r *Root) error {
 			}
 		}
 
-		r.Services[s.ServiceType] = s
+		r.addService(s)
 	}
 	for _, d2 := range d.Devices {
-		if err := d2.fillServices(r); err != nil {
+		if err := d2.fillServices(ctx, r, source); err != nil {
 			return fmt.Errorf("could not fill services: %w", err)
 		}
 	}
 	return nil
 }
 
-// Call an action.
-// Currently only actions without input arguments are supported.
+// Call an action without input arguments.
 func (a *Action) Call() (Result, error) {
+	return a.CallWithArgsContext(context.Background(), nil)
+}
+
+// CallContext calls an action without input arguments.
+// The SOAP request is aborted when ctx is done.
+func (a *Action) CallContext(ctx context.Context) (Result, error) {
+	return a.CallWithArgsContext(ctx, nil)
+}
+
+// CallWithArgs calls an action with the given input arguments.
+// The arguments are indexed by the argument name (e.g. NewIndex) and are
+// type-checked against the DataType of the related state variable.
+// All input arguments of the action have to be given.
+func (a *Action) CallWithArgs(args map[string]interface{}) (Result, error) {
+	return a.CallWithArgsContext(context.Background(), args)
+}
+
+// CallWithArgsContext is like CallWithArgs, but aborts the SOAP request when ctx is done.
+func (a *Action) CallWithArgsContext(ctx context.Context, args map[string]interface{}) (Result, error) {
+	argsXML, err := a.encodeArguments(args)
+	if err != nil {
+		return nil, err
+	}
+
 	bodystr := fmt.Sprintf(`
         <?xml version='1.0' encoding='utf-8'?>
         <s:Envelope s:encodingStyle='http://schemas.xmlsoap.org/soap/encoding/' xmlns:s='http://schemas.xmlsoap.org/soap/envelope/'>
             <s:Body>
-                <u:%s xmlns:u='%s' />
+                <u:%s xmlns:u='%s'>%s</u:%s>
             </s:Body>
         </s:Envelope>
-    `, a.Name, a.service.ServiceType)
+    `, a.Name, a.service.ServiceType, argsXML, a.Name)
 
-	url := a.service.Device.root.BaseURL + a.service.ControlURL
+	root := a.service.Device.root
+	baseURL := root.BaseURL
+	if root.SecureBaseURL != "" {
+		baseURL = root.SecureBaseURL
+	}
+	url := baseURL + a.service.ControlURL
 	body := strings.NewReader(bodystr)
 
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("could not create new request: %w", err)
 	}
@@ -241,11 +335,11 @@ func (a *Action) Call() (Result, error) {
 	req.Header.Set("SoapAction", action)
 
 	// Add digest authentification
-	t := dac.NewTransport(a.service.Device.root.Username, a.service.Device.root.Password)
-	resp, err := t.RoundTrip(req)
+	resp, err := root.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("could not roundtrip digest authentification: %w", err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, errors.New("authorization required")
 	}
@@ -255,10 +349,56 @@ func (a *Action) Call() (Result, error) {
 		return nil, fmt.Errorf("could not read body: %w", err)
 	}
 
+	if fault := parseSOAPFault(data.Bytes()); fault != nil {
+		return nil, fault
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
 	return a.parseSoapResponse(data)
 
 }
 
+// encodeArguments serializes the input arguments into their SOAP representation.
+// The elements are written in the order of the action's argument list.
+func (a *Action) encodeArguments(args map[string]interface{}) (string, error) {
+	for name := range args {
+		arg, ok := a.ArgumentMap[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s has no argument %s", ErrInvalidArgument, a.Name, name)
+		}
+		if arg.Direction != "in" {
+			return "", fmt.Errorf("%w: %s is not an input argument of %s", ErrInvalidArgument, name, a.Name)
+		}
+	}
+
+	var buf strings.Builder
+	for _, arg := range a.Arguments {
+		if arg.Direction != "in" {
+			continue
+		}
+		val, ok := args[arg.Name]
+		if !ok {
+			return "", fmt.Errorf("%w: missing input argument %s for %s", ErrInvalidArgument, arg.Name, a.Name)
+		}
+		str, err := convertArgument(val, arg)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrInvalidArgument, arg.Name, err)
+		}
+		if err := arg.StateVariable.validate(str); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrInvalidArgument, arg.Name, err)
+		}
+
+		buf.WriteString("<" + arg.Name + ">")
+		if err := xml.EscapeText(&buf, []byte(str)); err != nil {
+			return "", fmt.Errorf("could not escape argument %s: %w", arg.Name, err)
+		}
+		buf.WriteString("</" + arg.Name + ">")
+	}
+	return buf.String(), nil
+}
+
 func (a *Action) parseSoapResponse(r io.Reader) (Result, error) {
 	res := make(Result)
 	dec := xml.NewDecoder(r)
@@ -303,23 +443,42 @@ func (a *Action) parseSoapResponse(r io.Reader) (Result, error) {
 	}
 }
 
+// counters64 are the state variables of the 64 bit counters AVM declares as string.
+// cmd/scpdgen has the same list.
+var counters64 = map[string]bool{
+	"X_AVM_DE_TotalBytesSent64":     true, // IGD
+	"X_AVM_DE_TotalBytesReceived64": true,
+	"X_AVM-DE_TotalBytesSent64":     true, // TR-064
+	"X_AVM-DE_TotalBytesReceived64": true,
+}
+
 func convertResult(val string, arg *Argument) (interface{}, error) {
-	switch arg.StateVariable.Name {
-	case "X_AVM_DE_TotalBytesSent64", "X_AVM_DE_TotalBytesReceived64":
-		res, err := strconv.ParseUint(val, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse uint: %w", err)
+	dataType := arg.StateVariable.DataType
+
+	// AVM declares its 64 bit counters like X_AVM_DE_TotalBytesSent64 as string,
+	// and leaves them empty if there is no value
+	if dataType == "string" && counters64[arg.StateVariable.Name] {
+		if val == "" {
+			return uint64(0), nil
 		}
-		return uint64(res), nil
+		if res, err := strconv.ParseUint(val, 10, 64); err == nil {
+			return res, nil
+		}
+		return val, nil
 	}
 
-	switch arg.StateVariable.DataType {
-	case "string":
+	switch dataType {
+	case "string", "char", "uuid", "uri":
 		return val, nil
 	case "boolean":
-		return bool(val == "1"), nil
+		switch strings.ToLower(val) {
+		case "1", "true", "yes":
+			return true, nil
+		default:
+			return false, nil
+		}
 
-	case "ui1", "ui2", "ui4":
+	case "ui1", "ui2", "ui4", "ui8":
 		// type ui4 can contain values greater than 2^32!
 		res, err := strconv.ParseUint(val, 10, 64)
 		if err != nil {
@@ -327,7 +486,7 @@ func convertResult(val string, arg *Argument) (interface{}, error) {
 		}
 		return uint64(res), nil
 
-	case "i1", "i2", "i4":
+	case "i1", "i2", "i4", "i8", "int":
 		// type i4 can contain values greater than 2^32!, 2^64 to be precise. ParseInt returns int64 anyways.
 		res, err := strconv.ParseInt(val, 10, 64)
 		if err != nil {
@@ -335,6 +494,46 @@ func convertResult(val string, arg *Argument) (interface{}, error) {
 		}
 		return int64(res), nil
 
+	case "r4", "r8", "number", "float", "fixed.14.4":
+		res, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse float: %w", err)
+		}
+		return res, nil
+
+	case "bin.base64":
+		res, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode base64: %w", err)
+		}
+		return res, nil
+
+	case "bin.hex":
+		res, err := hex.DecodeString(val)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode hex: %w", err)
+		}
+		return res, nil
+
+	case "date":
+		res, err := time.Parse(dateLayout, val)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse date: %w", err)
+		}
+		return res, nil
+
+	case "time", "time.tz":
+		// Like dateTime, the time zone is optional
+		res, err := time.Parse(timeTZLayout, val)
+		if err == nil {
+			return res, nil
+		}
+		res, err = time.Parse(timeLayout, val)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse time: %w", err)
+		}
+		return res, nil
+
 	case "dateTime":
 		// UPnP uses ISO8601 (non-strict RFC3339) with optional TZ.
 		// try RFC3339 first
@@ -345,9 +544,9 @@ func convertResult(val string, arg *Argument) (interface{}, error) {
 		}
 		// if RFC3339 fails, try without TZ
 		res, err = time.Parse(RFC3339_WITHOUT_TZ, val)
-			if err != nil {
-				return nil, fmt.Errorf("could not parse dateTime: %w", err)
-			}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse dateTime: %w", err)
+		}
 		return res, nil
 
 	case "dateTime.tz":
@@ -357,35 +556,217 @@ func convertResult(val string, arg *Argument) (interface{}, error) {
 		}
 		return res, nil
 	default:
-		return nil, fmt.Errorf("unknown datatype: %s", arg.StateVariable.DataType)
+		warnUnknownDataType(dataType)
+		return val, nil
 	}
 }
 
-// LoadServices loads the services tree from a device.
-func LoadServices(device string, port uint16, username string, password string) (*Root, error) {
-	root := &Root{
-		BaseURL:  fmt.Sprintf("http://%s:%d", device, port),
-		Username: username,
-		Password: password,
+// unknownDataTypes remembers the data types warned about, to log each one only once
+var unknownDataTypes sync.Map
+
+// warnUnknownDataType logs that values of the data type are returned as string
+func warnUnknownDataType(dataType string) {
+	if _, warned := unknownDataTypes.LoadOrStore(dataType, true); !warned {
+		log.Printf("unknown datatype %s, returning the values as string", dataType)
 	}
+}
 
-	if err := root.load(); err != nil {
-		return nil, fmt.Errorf("could not load root element: %w", err)
+// convertArgument converts an input argument into its string representation
+// after checking that the Go type fits the DataType of the related state variable.
+func convertArgument(val interface{}, arg *Argument) (string, error) {
+	if arg.StateVariable == nil {
+		return "", fmt.Errorf("unknown state variable %s", arg.RelatedStateVariable)
 	}
 
-	rootTr64 := &Root{
-		BaseURL:  fmt.Sprintf("http://%s:%d", device, port),
-		Username: username,
-		Password: password,
+	switch dt := arg.StateVariable.DataType; dt {
+	case "string", "uuid", "uri":
+		str, ok := val.(string)
+		if !ok {
+			return "", fmt.Errorf("expected string, got %T", val)
+		}
+		return str, nil
+
+	case "char":
+		str, ok := val.(string)
+		if !ok {
+			return "", fmt.Errorf("expected string, got %T", val)
+		}
+		if utf8.RuneCountInString(str) != 1 {
+			return "", fmt.Errorf("expected a single character, got %q", str)
+		}
+		return str, nil
+
+	case "boolean":
+		b, ok := val.(bool)
+		if !ok {
+			return "", fmt.Errorf("expected bool, got %T", val)
+		}
+		if b {
+			return "1", nil
+		}
+		return "0", nil
+
+	case "ui1", "ui2", "ui4", "ui8":
+		u, err := toUint64(val)
+		if err != nil {
+			return "", err
+		}
+		if u > maxUint(dt) {
+			return "", fmt.Errorf("value %d overflows %s", u, dt)
+		}
+		return strconv.FormatUint(u, 10), nil
+
+	case "i1", "i2", "i4", "i8", "int":
+		i, err := toInt64(val)
+		if err != nil {
+			return "", err
+		}
+		if min, max := intRange(dt); i < min || i > max {
+			return "", fmt.Errorf("value %d overflows %s", i, dt)
+		}
+		return strconv.FormatInt(i, 10), nil
+
+	case "r4", "r8", "number", "float", "fixed.14.4":
+		f, err := toFloat64(val)
+		if err != nil {
+			return "", err
+		}
+		if dt == "fixed.14.4" {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+
+	case "bin.base64", "bin.hex":
+		b, ok := val.([]byte)
+		if !ok {
+			return "", fmt.Errorf("expected []byte, got %T", val)
+		}
+		if dt == "bin.hex" {
+			return hex.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+
+	case "date", "time", "time.tz", "dateTime", "dateTime.tz":
+		t, ok := val.(time.Time)
+		if !ok {
+			return "", fmt.Errorf("expected time.Time, got %T", val)
+		}
+		return t.Format(timeLayouts[dt]), nil
+
+	default:
+		// Pass values of unknown types through, like convertResult does
+		str, ok := val.(string)
+		if !ok {
+			return "", fmt.Errorf("unknown datatype %s, expected string, got %T", dt, val)
+		}
+		return str, nil
+	}
+}
+
+// timeLayouts are the layouts to format time.Time input arguments with
+var timeLayouts = map[string]string{
+	"date":        dateLayout,
+	"time":        timeLayout,
+	"time.tz":     timeTZLayout,
+	"dateTime":    RFC3339_WITHOUT_TZ,
+	"dateTime.tz": time.RFC3339,
+}
+
+func toFloat64(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+
+	if i, err := toInt64(val); err == nil {
+		return float64(i), nil
+	}
+	if u, err := toUint64(val); err == nil {
+		return float64(u), nil
+	}
+	return 0, fmt.Errorf("expected number, got %T", val)
+}
+
+func toUint64(val interface{}) (uint64, error) {
+	switch v := val.(type) {
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	}
+
+	i, err := toInt64(val)
+	if err != nil {
+		return 0, fmt.Errorf("expected unsigned integer, got %T", val)
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("expected unsigned integer, got %d", i)
 	}
+	return uint64(i), nil
+}
 
-	if err := rootTr64.loadTr64(); err != nil {
-		return nil, fmt.Errorf("could not load Tr64: %w", err)
+func toInt64(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", val)
+	}
+}
+
+func maxUint(dataType string) uint64 {
+	switch dataType {
+	case "ui1":
+		return math.MaxUint8
+	case "ui2":
+		return math.MaxUint16
+	case "ui4":
+		return math.MaxUint32
+	default:
+		return math.MaxUint64
 	}
+}
 
-	for k, v := range rootTr64.Services {
-		root.Services[k] = v
+func intRange(dataType string) (int64, int64) {
+	switch dataType {
+	case "i1":
+		return math.MinInt8, math.MaxInt8
+	case "i2":
+		return math.MinInt16, math.MaxInt16
+	case "i4":
+		return math.MinInt32, math.MaxInt32
+	default:
+		return math.MinInt64, math.MaxInt64
 	}
+}
 
-	return root, nil
+// LoadServices loads the services tree from a device.
+func LoadServices(device string, port uint16, username string, password string) (*Root, error) {
+	return LoadServicesContext(context.Background(), device, port, username, password)
+}
+
+// LoadServicesContext loads the services tree from a device using a new Client.
+// Loading is aborted when ctx is done.
+func LoadServicesContext(ctx context.Context, device string, port uint16, username string, password string) (*Root, error) {
+	client := NewClient(ClientOptions{
+		Username: username,
+		Password: password,
+	})
+	return client.LoadServices(ctx, device, port)
 }
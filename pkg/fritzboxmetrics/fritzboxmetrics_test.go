@@ -0,0 +1,239 @@
+package fritzboxmetrics_test
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+const (
+	testUsername = "exporter"
+	testPassword = "secret"
+
+	hostsService = "urn:dslforum-org:service:Hosts:1"
+	wanService   = "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1"
+)
+
+// loadServices starts a fake FRITZ!Box and loads its services tree
+func loadServices(t *testing.T) (*fritzboxtest.Server, *fritzboxmetrics.Root) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	t.Cleanup(s.Close)
+
+	root, err := fritzboxmetrics.LoadServices(s.Host(), s.Port(), testUsername, testPassword)
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+	return s, root
+}
+
+// action returns the action of the first service of the type
+func action(t *testing.T, root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource, serviceType, name string) *fritzboxmetrics.Action {
+	service, ok := root.Service(source, serviceType)
+	if !ok {
+		t.Fatalf("service %s not loaded", serviceType)
+	}
+	a, ok := service.Actions[name]
+	if !ok {
+		t.Fatalf("action %s of %s not loaded", name, serviceType)
+	}
+	return a
+}
+
+func TestLoadServices(t *testing.T) {
+	_, root := loadServices(t)
+
+	for _, tc := range []struct {
+		source      fritzboxmetrics.ServiceSource
+		serviceType string
+		instances   int
+	}{
+		{fritzboxmetrics.SourceIGD, wanService, 1},
+		{fritzboxmetrics.SourceIGD, "urn:schemas-upnp-org:service:WANIPConnection:1", 1},
+		{fritzboxmetrics.SourceTR64, "urn:dslforum-org:service:DeviceInfo:1", 1},
+		{fritzboxmetrics.SourceTR64, hostsService, 1},
+		{fritzboxmetrics.SourceTR64, "urn:dslforum-org:service:WLANConfiguration:1", 3},
+		{fritzboxmetrics.SourceTR64, "urn:dslforum-org:service:LANEthernetInterfaceConfig:1", 2},
+	} {
+		services := root.ServicesByType(tc.source, tc.serviceType)
+		if len(services) != tc.instances {
+			t.Errorf("got %d instances of %s, want %d", len(services), tc.serviceType, tc.instances)
+			continue
+		}
+		for i, s := range services {
+			if s.Instance() != i+1 {
+				t.Errorf("instance %d of %s has number %d", i, tc.serviceType, s.Instance())
+			}
+			if len(s.Actions) == 0 {
+				t.Errorf("no actions loaded for %s", tc.serviceType)
+			}
+		}
+		if root.Services[tc.serviceType] != services[0] {
+			t.Errorf("Services holds instance %d of %s, want the first one", root.Services[tc.serviceType].Instance(), tc.serviceType)
+		}
+	}
+
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetGenericHostEntry")
+	arg, ok := a.ArgumentMap["NewIndex"]
+	if !ok || arg.Direction != "in" || arg.StateVariable == nil || arg.StateVariable.DataType != "ui2" {
+		t.Errorf("NewIndex of GetGenericHostEntry = %+v, want an ui2 input", arg)
+	}
+	if root.Bundle() == nil || len(root.Bundle().Documents) == 0 {
+		t.Error("downloaded descriptions are not bundled")
+	}
+}
+
+func TestLoadServicesContextCancel(t *testing.T) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{})
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fritzboxmetrics.LoadServicesContext(ctx, s.Host(), s.Port(), "", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestActionCall(t *testing.T) {
+	s, root := loadServices(t)
+	s.SetResponse(wanService, "GetAddonInfos", map[string]string{
+		"NewByteSendRate":    "1200",
+		"NewByteReceiveRate": "34000",
+	})
+
+	res, err := action(t, root, fritzboxmetrics.SourceIGD, wanService, "GetAddonInfos").Call()
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if res["ByteSendRate"] != uint64(1200) || res["ByteReceiveRate"] != uint64(34000) {
+		t.Errorf("got %v", res)
+	}
+}
+
+func TestActionCallWithArgs(t *testing.T) {
+	s, root := loadServices(t)
+
+	var got map[string]string
+	s.Handle(hostsService, "GetGenericHostEntry", func(args map[string]string) (map[string]string, error) {
+		got = args
+		if args["NewIndex"] != "1" {
+			return nil, &fritzboxtest.Fault{UPnPErrorCode: 713, UPnPErrorDescription: "SpecifiedArrayIndexInvalid"}
+		}
+		return map[string]string{
+			"NewIPAddress":  "192.168.178.21",
+			"NewMACAddress": "02:00:00:00:00:02",
+			"NewActive":     "1",
+			"NewHostName":   "laptop",
+		}, nil
+	})
+
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetGenericHostEntry")
+	res, err := a.CallWithArgs(map[string]interface{}{"NewIndex": 1})
+	if err != nil {
+		t.Fatalf("CallWithArgs: %v", err)
+	}
+	if got["NewIndex"] != "1" {
+		t.Errorf("device got arguments %v, want NewIndex 1", got)
+	}
+	if res["HostName"] != "laptop" || res["Active"] != true {
+		t.Errorf("got %v", res)
+	}
+	if s.Calls(hostsService, "GetGenericHostEntry") != 1 {
+		t.Errorf("action was called %d times, want 1", s.Calls(hostsService, "GetGenericHostEntry"))
+	}
+
+	if _, err := a.CallWithArgs(map[string]interface{}{"NewIndex": 5}); !errors.Is(err, fritzboxmetrics.ErrSpecifiedArrayIndexInvalid) {
+		t.Errorf("got %v, want ErrSpecifiedArrayIndexInvalid", err)
+	}
+	for _, args := range []map[string]interface{}{
+		nil,
+		{"NewIndex": -1},
+		{"NewIndex": 70000},
+		{"NewIndex": 1, "NewUnknown": 1},
+	} {
+		if _, err := a.CallWithArgs(args); err == nil {
+			t.Errorf("CallWithArgs(%v) succeeded, want an error", args)
+		}
+	}
+	if s.Calls(hostsService, "GetGenericHostEntry") != 2 {
+		t.Error("invalid arguments were sent to the device")
+	}
+}
+
+func TestActionCallFaults(t *testing.T) {
+	s, root := loadServices(t)
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries")
+
+	s.InjectFault(hostsService, "GetHostNumberOfEntries", fritzboxtest.Fault{UPnPErrorCode: 606, UPnPErrorDescription: "Action not authorized"})
+	_, err := a.Call()
+	var fault *fritzboxmetrics.SOAPFault
+	if !errors.As(err, &fault) || fault.ErrorCode != 606 || fault.ErrorDescription != "Action not authorized" {
+		t.Errorf("got %v, want a SOAP fault with UPnP error 606", err)
+	}
+	if !errors.Is(err, fritzboxmetrics.ErrActionNotAuthorized) {
+		t.Errorf("got %v, want ErrActionNotAuthorized", err)
+	}
+
+	s.InjectFault(hostsService, "GetHostNumberOfEntries", fritzboxtest.Fault{StatusCode: http.StatusInternalServerError})
+	if _, err := a.Call(); err == nil || errors.As(err, &fault) {
+		t.Errorf("got %v, want an error without SOAP fault", err)
+	}
+
+	// Actions without handler are unknown to the fake
+	s.ClearFaults()
+	if _, err := a.Call(); !errors.Is(err, fritzboxmetrics.ErrInvalidAction) {
+		t.Errorf("got %v, want ErrInvalidAction", err)
+	}
+}
+
+func TestActionCallContext(t *testing.T) {
+	s, root := loadServices(t)
+	s.SetResponse(hostsService, "GetHostNumberOfEntries", map[string]string{"NewHostNumberOfEntries": "3"})
+	s.InjectFault(hostsService, "GetHostNumberOfEntries", fritzboxtest.Fault{Delay: 5 * time.Second})
+	a := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := a.CallContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("call returned after %v, want it to be aborted", d)
+	}
+}
+
+func TestActionCallUnauthorized(t *testing.T) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: testUsername, Password: testPassword})
+	defer s.Close()
+	s.SetResponse(hostsService, "GetHostNumberOfEntries", map[string]string{"NewHostNumberOfEntries": "3"})
+
+	root, err := fritzboxmetrics.LoadServices(s.Host(), s.Port(), testUsername, "wrong")
+	if err != nil {
+		t.Fatalf("LoadServices: %v", err)
+	}
+	if _, err := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetHostNumberOfEntries").Call(); err == nil {
+		t.Error("call with a wrong password succeeded")
+	}
+	if s.Calls(hostsService, "GetHostNumberOfEntries") != 0 {
+		t.Error("unauthenticated call reached the action")
+	}
+}
@@ -0,0 +1,356 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// recordingIndex is the file in a recording directory listing all exchanges
+	recordingIndex = "recording.json"
+	// recordedSID replaces every valid session ID, so a replayed login yields
+	// the session ID of the recorded requests
+	recordedSID = "00000000000000ff"
+)
+
+var (
+	macAddressPattern = regexp.MustCompile(`\b([0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}\b`)
+	// sidPattern matches session IDs in queries, forms and the <SID> of login_sid.lua
+	sidPattern = regexp.MustCompile(`(\bsid=|<SID>)[0-9a-fA-F]{16}\b`)
+	// secretElementPattern matches XML elements carrying credentials or keys,
+	// e.g. <NewX_AVM-DE_Password> or <NewKeyPassphrase>
+	secretElementPattern = regexp.MustCompile(`(?is)<([\w:.-]*(?:password|passphrase|presharedkey|wepkey|username|secret)[\w.-]*)>[^<]*</`)
+	// elementValuePattern matches the text of an XML element
+	elementValuePattern = regexp.MustCompile(`>([^<]+)</`)
+	// formValuePattern matches a value of a query or a form, e.g. the username of a login
+	formValuePattern = regexp.MustCompile(`(^|[?&])([\w.-]+)=([^&\s]*)`)
+)
+
+// Exchange is a recorded request/response pair
+type Exchange struct {
+	Method       string `json:"method"`
+	Path         string `json:"path"` // Path and query of the request
+	SOAPAction   string `json:"soap_action,omitempty"`
+	RequestFile  string `json:"request_file,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	ResponseFile string `json:"response_file"`
+}
+
+// RecorderOptions configure a Recorder
+type RecorderOptions struct {
+	// Transport records the exchanges of this transport, http.DefaultTransport if nil
+	Transport http.RoundTripper
+	// Redact lists additional values which are replaced in the recording, e.g. the username.
+	// Only XML element values and query or form values equal to one of them are replaced.
+	Redact []string
+}
+
+// Recorder is a http.RoundTripper which records all exchanges.
+// Use it as ClientOptions.Transport and write the recording with WriteDir.
+// Authentication challenges aren't recorded. MAC addresses, session IDs,
+// credentials and keys are redacted in the recording.
+type Recorder struct {
+	transport http.RoundTripper
+	redact    []string
+
+	mu        sync.Mutex // protects the fields below
+	exchanges []recordedExchange
+	macs      map[string]string // Real MAC address to pseudonym
+}
+
+type recordedExchange struct {
+	Exchange
+	request  []byte
+	response []byte
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder(opts RecorderOptions) *Recorder {
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		transport: transport,
+		redact:    opts.Redact,
+		macs:      make(map[string]string),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, recordedExchange{
+		Exchange: Exchange{
+			Method:     req.Method,
+			Path:       r.redactString(r.redactFormValues(req.URL.RequestURI())),
+			SOAPAction: req.Header.Get("SoapAction"),
+			StatusCode: resp.StatusCode,
+		},
+		request:  []byte(r.redactBody(string(reqBody))),
+		response: []byte(r.redactBody(string(respBody))),
+	})
+	return resp, nil
+}
+
+// redactBody replaces sensitive data in a request or response body. r.mu has to be held.
+func (r *Recorder) redactBody(s string) string {
+	s = elementValuePattern.ReplaceAllStringFunc(s, func(m string) string {
+		value := m[1 : len(m)-2]
+		if r.isRedacted(strings.TrimSpace(value)) {
+			return ">REDACTED</"
+		}
+		return m
+	})
+	return r.redactString(r.redactFormValues(s))
+}
+
+// redactFormValues replaces query or form values which are to be redacted
+func (r *Recorder) redactFormValues(s string) string {
+	return formValuePattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := formValuePattern.FindStringSubmatch(m)
+		value, err := url.QueryUnescape(sub[3])
+		if err != nil || !r.isRedacted(value) {
+			return m
+		}
+		return sub[1] + sub[2] + "=REDACTED"
+	})
+}
+
+// isRedacted reports whether the value is one of the values to redact
+func (r *Recorder) isRedacted(value string) bool {
+	for _, secret := range r.redact {
+		if secret != "" && value == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the sensitive data recognized by its form. r.mu has to be held.
+func (r *Recorder) redactString(s string) string {
+	s = secretElementPattern.ReplaceAllString(s, "<$1>REDACTED</")
+	s = sidPattern.ReplaceAllStringFunc(s, func(m string) string {
+		prefix, sid := m[:len(m)-16], m[len(m)-16:]
+		if sid == invalidSID {
+			return m
+		}
+		return prefix + recordedSID
+	})
+
+	// Keep the pseudonyms consistent, so hosts can be matched across responses
+	return macAddressPattern.ReplaceAllStringFunc(s, func(mac string) string {
+		key := strings.ToUpper(strings.ReplaceAll(mac, "-", ":"))
+		pseudonym, ok := r.macs[key]
+		if !ok {
+			n := len(r.macs) + 1
+			pseudonym = fmt.Sprintf("02:00:00:00:%02X:%02X", n>>8&0xff, n&0xff)
+			r.macs[key] = pseudonym
+		}
+		return pseudonym
+	})
+}
+
+// WriteDir writes the recording into dir. Description documents are written
+// with their original file name, SOAP exchanges into the soap subdirectory.
+func (r *Recorder) WriteDir(dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(dir, "soap"), 0755); err != nil {
+		return fmt.Errorf("could not create recording directory: %w", err)
+	}
+
+	index := make([]Exchange, 0, len(r.exchanges))
+	for i, ex := range r.exchanges {
+		e := ex.Exchange
+		if e.Method == http.MethodGet {
+			// SCPDs shared by several service instances are fetched once per instance
+			if recorded(index, e) {
+				continue
+			}
+			name := path.Base(strings.SplitN(e.Path, "?", 2)[0])
+			e.ResponseFile = uniqueName(index, name, i)
+		} else {
+			prefix := fmt.Sprintf("soap/%04d-%s", i, soapActionName(e.SOAPAction))
+			e.RequestFile = prefix + "-request.xml"
+			e.ResponseFile = prefix + "-response.xml"
+			if err := ioutil.WriteFile(filepath.Join(dir, e.RequestFile), ex.request, 0644); err != nil {
+				return fmt.Errorf("could not write request: %w", err)
+			}
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, e.ResponseFile), ex.response, 0644); err != nil {
+			return fmt.Errorf("could not write response: %w", err)
+		}
+		index = append(index, e)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode recording index: %w", err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, recordingIndex), data, 0644)
+}
+
+// recorded reports whether a GET of the same path is already in the index
+func recorded(index []Exchange, e Exchange) bool {
+	for _, other := range index {
+		if other.Method == http.MethodGet && other.Path == e.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueName returns name unless it's already used in the index
+func uniqueName(index []Exchange, name string, i int) string {
+	for _, e := range index {
+		if e.ResponseFile == name {
+			return fmt.Sprintf("%04d-%s", i, name)
+		}
+	}
+	return name
+}
+
+// soapActionName returns a file name friendly form of a SoapAction header,
+// e.g. DeviceInfo-GetInfo for urn:dslforum-org:service:DeviceInfo:1#GetInfo
+func soapActionName(soapAction string) string {
+	parts := strings.SplitN(soapAction, "#", 2)
+	if len(parts) != 2 {
+		return "unknown"
+	}
+	typeParts := strings.Split(parts[0], ":")
+	service := parts[0]
+	if len(typeParts) >= 2 {
+		service = typeParts[len(typeParts)-2]
+	}
+	return service + "-" + parts[1]
+}
+
+// ReplayTransport is a http.RoundTripper which answers requests from a recording
+// written by Recorder.WriteDir. Requests are matched by method, path and SoapAction;
+// if several exchanges match, the one with the same request body is preferred.
+type ReplayTransport struct {
+	dir       string
+	exchanges []Exchange
+}
+
+// NewReplayTransport loads the recording in dir
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, recordingIndex))
+	if err != nil {
+		return nil, fmt.Errorf("could not read recording index: %w", err)
+	}
+
+	t := &ReplayTransport{dir: dir}
+	if err := json.Unmarshal(data, &t.exchanges); err != nil {
+		return nil, fmt.Errorf("could not decode recording index: %w", err)
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		req.Body.Close()
+	}
+
+	var match *Exchange
+	for i := range t.exchanges {
+		e := &t.exchanges[i]
+		if e.Method != req.Method || e.Path != req.URL.RequestURI() || e.SOAPAction != req.Header.Get("SoapAction") {
+			continue
+		}
+		if match == nil {
+			match = e
+		}
+		if e.RequestFile == "" {
+			break
+		}
+		recorded, err := ioutil.ReadFile(filepath.Join(t.dir, e.RequestFile))
+		if err == nil && bytes.Equal(bytes.TrimSpace(recorded), bytes.TrimSpace(reqBody)) {
+			match = e
+			break
+		}
+	}
+
+	resp := &http.Response{
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Request:    req,
+	}
+	if match == nil {
+		resp.StatusCode = http.StatusNotFound
+		resp.Status = "404 Not Found"
+		resp.Body = ioutil.NopCloser(strings.NewReader(""))
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadFile(filepath.Join(t.dir, match.ResponseFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read recorded response: %w", err)
+	}
+	resp.StatusCode = match.StatusCode
+	resp.Status = fmt.Sprintf("%d %s", match.StatusCode, http.StatusText(match.StatusCode))
+	resp.Header.Set("Content-Type", textXML)
+	resp.ContentLength = int64(len(body))
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,141 @@
+package fritzboxmetrics_test
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+const voipService = "urn:dslforum-org:service:X_VoIP:1"
+
+func TestRecordReplay(t *testing.T) {
+	const (
+		username = "recorder"
+		password = "pa55w0rd"
+		realMAC  = "3C:A6:2F:12:34:56"
+	)
+
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: username, Password: password})
+	defer s.Close()
+	s.SetResponse(hostsService, "GetGenericHostEntry", map[string]string{
+		"NewIPAddress":          "192.168.178.20",
+		"NewAddressSource":      "DHCP",
+		"NewLeaseTimeRemaining": "0",
+		"NewMACAddress":         realMAC,
+		"NewInterfaceType":      "Ethernet",
+		"NewActive":             "1",
+		// The password as a value of an element which isn't a credential
+		"NewHostName": password,
+	})
+	s.SetResponse(voipService, "X_AVM-DE_GetVoIPAccount", map[string]string{
+		"NewVoIPRegistrar":     "tel.t-online.de",
+		"NewVoIPNumber":        "0301234567",
+		"NewVoIPUsername":      "0301234567",
+		"NewVoIPPassword":      "sip-secret",
+		"NewVoIPOutboundProxy": "",
+		"NewVoIPSTUNServer":    "",
+	})
+	s.SetWebPage("overview", `{"data":{"mac":"`+strings.ToLower(realMAC)+`"}}`)
+
+	recorder := fritzboxmetrics.NewRecorder(fritzboxmetrics.RecorderOptions{Redact: []string{username, password}})
+	client := fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: username, Password: password, Transport: recorder})
+	calls := func(client *fritzboxmetrics.Client) (fritzboxmetrics.Result, fritzboxmetrics.Result, []byte) {
+		t.Helper()
+		ctx := context.Background()
+		root, err := client.LoadServices(ctx, s.Host(), s.Port())
+		if err != nil {
+			t.Fatalf("LoadServices: %v", err)
+		}
+		host, err := action(t, root, fritzboxmetrics.SourceTR64, hostsService, "GetGenericHostEntry").CallWithArgsContext(ctx, map[string]interface{}{"NewIndex": 0})
+		if err != nil {
+			t.Fatalf("GetGenericHostEntry: %v", err)
+		}
+		account, err := action(t, root, fritzboxmetrics.SourceTR64, voipService, "X_AVM-DE_GetVoIPAccount").CallWithArgsContext(ctx, map[string]interface{}{"NewVoIPAccountIndex": 0})
+		if err != nil {
+			t.Fatalf("X_AVM-DE_GetVoIPAccount: %v", err)
+		}
+		session := client.NewSession(s.URL)
+		page, err := session.Post(ctx, "/data.lua", url.Values{"page": {"overview"}})
+		if err != nil {
+			t.Fatalf("Post: %v", err)
+		}
+		return host, account, page
+	}
+
+	calls(client)
+	if s.Sessions() != 1 {
+		t.Fatalf("got %d sessions, want 1", s.Sessions())
+	}
+	sid := "0000000000000001"
+
+	dir := t.TempDir()
+	if err := recorder.WriteDir(dir); err != nil {
+		t.Fatalf("WriteDir: %v", err)
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content := strings.ToUpper(string(data))
+		for _, secret := range []string{username, password, "sip-secret", sid, realMAC} {
+			if strings.Contains(content, strings.ToUpper(secret)) {
+				t.Errorf("%s contains %q", path, secret)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := fritzboxmetrics.NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("NewReplayTransport: %v", err)
+	}
+	host, account, page := calls(fritzboxmetrics.NewClient(fritzboxmetrics.ClientOptions{Username: username, Password: password, Transport: replay}))
+
+	if got := host["MACAddress"]; got != "02:00:00:00:00:01" {
+		t.Errorf("got MAC address %v, want the pseudonym", got)
+	}
+	if got := host["HostName"]; got != "REDACTED" {
+		t.Errorf("got host name %v, want it redacted", got)
+	}
+	if got := host["IPAddress"]; got != "192.168.178.20" {
+		t.Errorf("got IP address %v, values which aren't secret are changed", got)
+	}
+	if got := account["VoIPPassword"]; got != "REDACTED" {
+		t.Errorf("got VoIP password %v, want it redacted", got)
+	}
+	if got := account["VoIPNumber"]; got != "0301234567" {
+		t.Errorf("got VoIP number %v", got)
+	}
+	if want := `{"data":{"mac":"02:00:00:00:00:01"}}`; string(page) != want {
+		t.Errorf("got page %s, want %s", page, want)
+	}
+}
@@ -0,0 +1,134 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ServiceSource tells from which description tree a service instance was loaded
+type ServiceSource string
+
+const (
+	SourceIGD  ServiceSource = "igd"  // UPnP Internet Gateway Device, igddesc.xml
+	SourceTR64 ServiceSource = "tr64" // TR-064, tr64desc.xml
+)
+
+// ServiceKey identifies a service instance.
+// The same service type may be offered by several devices or several times by
+// one device (e.g. one WLANConfiguration per radio), each with its own control URL.
+type ServiceKey struct {
+	UDN        string
+	ServiceID  string
+	ControlURL string
+}
+
+// Key returns the key of the service instance
+func (s *Service) Key() ServiceKey {
+	return ServiceKey{
+		UDN:        s.Device.UDN,
+		ServiceID:  s.ServiceID,
+		ControlURL: s.ControlURL,
+	}
+}
+
+// Instance returns the instance number of the service, taken from the trailing
+// digits of its ServiceID (e.g. 2 for urn:WLANConfiguration-com:serviceId:WLANConfiguration2).
+// It returns 1 if the ServiceID has no trailing number.
+func (s *Service) Instance() int {
+	digits := strings.TrimRightFunc(s.ServiceID, unicode.IsDigit)
+	n, err := strconv.Atoi(s.ServiceID[len(digits):])
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// addService registers a service instance in the lookup maps of the root.
+// Services keeps the first instance of every service type in the order of
+// ServicesByType, independent of the order the instances are added in.
+func (r *Root) addService(s *Service) {
+	r.Instances[s.Key()] = s
+	if first, ok := r.Services[s.ServiceType]; !ok || serviceLess(s, first) {
+		r.Services[s.ServiceType] = s
+	}
+}
+
+// serviceLess orders service instances by source, device and instance number
+func serviceLess(a, b *Service) bool {
+	if a.Source != b.Source {
+		return a.Source < b.Source
+	}
+	if a.Device.UDN != b.Device.UDN {
+		return a.Device.UDN < b.Device.UDN
+	}
+	if a.Instance() != b.Instance() {
+		return a.Instance() < b.Instance()
+	}
+	return a.ControlURL < b.ControlURL
+}
+
+// ServicesByType returns all instances of a service type from the given source,
+// or from both sources if source is empty. The instances are ordered by
+// source, device and instance number.
+func (r *Root) ServicesByType(source ServiceSource, serviceType string) []*Service {
+	var services []*Service
+	for _, s := range r.Instances {
+		if s.ServiceType != serviceType {
+			continue
+		}
+		if source != "" && s.Source != source {
+			continue
+		}
+		services = append(services, s)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return serviceLess(services[i], services[j])
+	})
+	return services
+}
+
+// Service returns the first instance of a service type from the given source,
+// or from any source if source is empty.
+func (r *Root) Service(source ServiceSource, serviceType string) (*Service, bool) {
+	services := r.ServicesByType(source, serviceType)
+	if len(services) == 0 {
+		return nil, false
+	}
+	return services[0], true
+}
+
+// Fetch downloads a document the service refers to, e.g. the path returned by
+// X_AVM-DE_GetHostListPath. Paths are resolved against the URL the calls are sent to.
+func (s *Service) Fetch(ctx context.Context, path string) ([]byte, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return s.Device.root.client.get(ctx, path)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	root := s.Device.root
+	baseURL := root.BaseURL
+	if root.SecureBaseURL != "" {
+		baseURL = root.SecureBaseURL
+	}
+	return root.client.get(ctx, baseURL+path)
+}
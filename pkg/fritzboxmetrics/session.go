@@ -0,0 +1,332 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf16"
+)
+
+const (
+	loginPath = "/login_sid.lua?version=2"
+
+	// invalidSID is the session ID the FRITZ!Box returns if there is no valid session
+	invalidSID = "0000000000000000"
+)
+
+var (
+	// ErrLoginFailed will be thrown if the FRITZ!Box rejects the credentials
+	ErrLoginFailed = errors.New("login failed")
+	// ErrSessionExpired will be thrown if a request is rejected even with a new session
+	ErrSessionExpired = errors.New("session expired")
+)
+
+// sessionInfo is the answer of login_sid.lua
+type sessionInfo struct {
+	SID       string `xml:"SID"`
+	Challenge string `xml:"Challenge"`
+	BlockTime int    `xml:"BlockTime"`
+	Users     []struct {
+		Name string `xml:",chardata"`
+		Last int    `xml:"last,attr"`
+	} `xml:"Users>User"`
+}
+
+// Session is a login to the web interface of the FRITZ!Box, which offers
+// data.lua and other pages with values not available through TR-064.
+// The session ID is cached and renewed when it expires. Use Close to log out.
+type Session struct {
+	client  *Client
+	baseURL string
+
+	mu  sync.Mutex // protects sid and serializes logins
+	sid string
+}
+
+// NewSession creates a session for the web interface at baseURL, e.g. http://fritz.box.
+// It logs in with the credentials of the client on first use.
+func (c *Client) NewSession(baseURL string) *Session {
+	return &Session{
+		client:  c,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// SID returns the current session ID, logging in if there is none
+func (s *Session) SID(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sid != "" {
+		return s.sid, nil
+	}
+	sid, err := s.login(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.sid = sid
+	return sid, nil
+}
+
+// Get fetches path with the session ID in the sid query parameter
+func (s *Session) Get(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	return s.do(ctx, func(sid string) (*http.Request, error) {
+		query := url.Values{}
+		for k, v := range params {
+			query[k] = v
+		}
+		query.Set("sid", sid)
+		return http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path+"?"+query.Encode(), nil)
+	})
+}
+
+// Post sends the form to path with the session ID in the sid field,
+// e.g. to /data.lua with page=docInfo.
+func (s *Session) Post(ctx context.Context, path string, form url.Values) ([]byte, error) {
+	return s.do(ctx, func(sid string) (*http.Request, error) {
+		body := url.Values{}
+		for k, v := range form {
+			body[k] = v
+		}
+		body.Set("sid", sid)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(body.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
+}
+
+// do sends the request created for the session ID. If the FRITZ!Box rejects
+// the session ID it logs in again and repeats the request once.
+func (s *Session) do(ctx context.Context, newRequest func(sid string) (*http.Request, error)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		sid, err := s.SID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := newRequest(sid)
+		if err != nil {
+			return nil, fmt.Errorf("could not create new request: %w", err)
+		}
+		data, err := s.client.send(req)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, ErrSessionExpired) || attempt > 0 {
+			return nil, err
+		}
+		s.invalidate(sid)
+	}
+}
+
+// invalidate forgets the session ID unless another request already renewed it
+func (s *Session) invalidate(sid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sid == sid {
+		s.sid = ""
+	}
+}
+
+// Close logs out of the session, if there is one
+func (s *Session) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sid == "" {
+		return nil
+	}
+	form := url.Values{"logout": {"1"}, "sid": {s.sid}}
+	s.sid = ""
+	if _, err := s.postLogin(ctx, form); err != nil {
+		return fmt.Errorf("could not log out: %w", err)
+	}
+	return nil
+}
+
+// login does the challenge-response login. s.mu has to be held.
+func (s *Session) login(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+loginPath, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create new request: %w", err)
+	}
+	data, err := s.client.send(req)
+	if err != nil {
+		return "", fmt.Errorf("could not get login challenge: %w", err)
+	}
+
+	var info sessionInfo
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return "", fmt.Errorf("could not decode login challenge: %w", err)
+	}
+	if info.SID != invalidSID && info.SID != "" {
+		// No password set on the FRITZ!Box
+		return info.SID, nil
+	}
+
+	response, err := challengeResponse(info.Challenge, s.client.password)
+	if err != nil {
+		return "", err
+	}
+
+	username := s.client.username
+	if username == "" {
+		// Boxes without user accounts log in as the last user
+		for _, u := range info.Users {
+			if u.Last == 1 {
+				username = u.Name
+			}
+		}
+	}
+
+	info, err = s.postLogin(ctx, url.Values{"username": {username}, "response": {response}})
+	if err != nil {
+		return "", err
+	}
+	if info.SID == invalidSID || info.SID == "" {
+		if info.BlockTime > 0 {
+			return "", fmt.Errorf("%w: blocked for %d seconds", ErrLoginFailed, info.BlockTime)
+		}
+		return "", ErrLoginFailed
+	}
+	return info.SID, nil
+}
+
+func (s *Session) postLogin(ctx context.Context, form url.Values) (sessionInfo, error) {
+	var info sessionInfo
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+loginPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return info, fmt.Errorf("could not create new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	data, err := s.client.send(req)
+	if err != nil {
+		return info, err
+	}
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("could not decode session info: %w", err)
+	}
+	return info, nil
+}
+
+// send sends a request of the web interface and returns the response body.
+// A rejected session ID is reported as ErrSessionExpired.
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	atomic.AddUint64(&c.requests, 1)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return nil, ErrSessionExpired
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read body: %w", err)
+	}
+	return data, nil
+}
+
+// challengeResponse answers a login challenge, either with PBKDF2
+// (challenges starting with 2$) or with MD5 on older firmware.
+func challengeResponse(challenge, password string) (string, error) {
+	if !strings.HasPrefix(challenge, "2$") {
+		return md5Response(challenge, password), nil
+	}
+
+	// 2$<iter1>$<salt1>$<iter2>$<salt2>
+	parts := strings.Split(challenge, "$")
+	if len(parts) != 5 {
+		return "", fmt.Errorf("invalid PBKDF2 challenge %q", challenge)
+	}
+	iter1, err1 := strconv.Atoi(parts[1])
+	salt1, err2 := hex.DecodeString(parts[2])
+	iter2, err3 := strconv.Atoi(parts[3])
+	salt2, err4 := hex.DecodeString(parts[4])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || iter1 <= 0 || iter2 <= 0 {
+		return "", fmt.Errorf("invalid PBKDF2 challenge %q", challenge)
+	}
+
+	hash1 := pbkdf2SHA256([]byte(password), salt1, iter1)
+	hash2 := pbkdf2SHA256(hash1, salt2, iter2)
+	return parts[4] + "$" + hex.EncodeToString(hash2), nil
+}
+
+// md5Response answers a legacy challenge with the MD5 of challenge-password
+// in UTF-16LE. Characters beyond Latin-1 are replaced by a dot, like the web interface does.
+func md5Response(challenge, password string) string {
+	runes := []rune(challenge + "-" + password)
+	for i, r := range runes {
+		if r > 255 {
+			runes[i] = '.'
+		}
+	}
+
+	units := utf16.Encode(runes)
+	buf := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[2*i:], u)
+	}
+	sum := md5.Sum(buf)
+	return challenge + "-" + hex.EncodeToString(sum[:])
+}
+
+// pbkdf2SHA256 derives a 32 byte key with PBKDF2-HMAC-SHA256 (RFC 8018),
+// which is all the login needs: a single block.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
@@ -0,0 +1,122 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxtest"
+)
+
+func TestChallengeResponse(t *testing.T) {
+	for _, tc := range []struct {
+		challenge string
+		password  string
+		want      string
+	}{
+		// Examples of AVM's technical note on the session ID
+		{"2$10000$5A1711$2000$5A1722", "1example!", "5A1722$1798a1672bca7c6463d6b245f82b53703b0f50813401b03e4045a5861e689adb"},
+		{"1234567z", "äbc", "1234567z-9e224a41eeefa284df7bb0f26c2913e2"},
+	} {
+		got, err := challengeResponse(tc.challenge, tc.password)
+		if err != nil {
+			t.Errorf("challengeResponse(%q): %v", tc.challenge, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("challengeResponse(%q) = %s, want %s", tc.challenge, got, tc.want)
+		}
+	}
+
+	for _, challenge := range []string{"2$10000$5A1711$2000", "2$0$5A1711$2000$5A1722", "2$10000$5A17XX$2000$5A1722"} {
+		if _, err := challengeResponse(challenge, "1example!"); err == nil {
+			t.Errorf("challengeResponse(%q) accepted an invalid challenge", challenge)
+		}
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	for _, tc := range []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	} {
+		if got := hex.EncodeToString(pbkdf2SHA256([]byte("password"), []byte("salt"), tc.iterations)); got != tc.want {
+			t.Errorf("%d iterations: got %s, want %s", tc.iterations, got, tc.want)
+		}
+	}
+}
+
+func TestSessionRelogin(t *testing.T) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: "exporter", Password: "secret"})
+	defer s.Close()
+	s.SetWebPage("overview", `{"data":{}}`)
+
+	ctx := context.Background()
+	client := NewClient(ClientOptions{Username: "exporter", Password: "secret"})
+	session := client.NewSession(s.URL)
+	page := url.Values{"page": {"overview"}}
+
+	if _, err := session.Post(ctx, "/data.lua", page); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	first, _ := session.SID(ctx)
+	if _, err := session.Post(ctx, "/data.lua", page); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if sid, _ := session.SID(ctx); sid != first || s.Sessions() != 1 {
+		t.Errorf("got session %s and %d sessions, want the session %s to be reused", sid, s.Sessions(), first)
+	}
+
+	s.ExpireSessions()
+	requests := client.Stats().Requests
+	if _, err := session.Post(ctx, "/data.lua", page); err != nil {
+		t.Fatalf("Post after the session expired: %v", err)
+	}
+	if sid, _ := session.SID(ctx); sid == first || s.Sessions() != 1 {
+		t.Errorf("got session %s and %d sessions, want a new session", sid, s.Sessions())
+	}
+	// Rejected request, challenge, login and repeated request
+	if got := client.Stats().Requests - requests; got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+
+	if err := session.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if s.Sessions() != 0 {
+		t.Errorf("got %d sessions after logging out, want 0", s.Sessions())
+	}
+}
+
+func TestSessionLoginFailed(t *testing.T) {
+	s := fritzboxtest.NewServer(fritzboxtest.Options{Username: "exporter", Password: "secret"})
+	defer s.Close()
+
+	client := NewClient(ClientOptions{Username: "exporter", Password: "wrong"})
+	if _, err := client.NewSession(s.URL).SID(context.Background()); !errors.Is(err, ErrLoginFailed) {
+		t.Errorf("got %v, want ErrLoginFailed", err)
+	}
+	if s.Sessions() != 0 {
+		t.Errorf("got %d sessions, want 0", s.Sessions())
+	}
+}
@@ -0,0 +1,136 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	deviceInfoService = "urn:dslforum-org:service:DeviceInfo:1"
+	securityPortArg   = "NewSecurityPort"
+)
+
+// securityPortActions are the known names of the action returning the security port
+var securityPortActions = []string{"GetSecurityPort", "X_AVM-DE_GetSecurityPort"}
+
+// ErrCertificateMismatch will be thrown if the certificate doesn't match the pinned fingerprint
+var ErrCertificateMismatch = errors.New("certificate fingerprint mismatch")
+
+// NewTLSConfig creates a TLS configuration for the self-signed certificate of the FRITZ!Box.
+// If caFile is given, the certificate has to be signed by one of the CAs in that PEM file.
+// If fingerprint is given, the SHA-256 fingerprint of the certificate (hex, optionally
+// separated by colons) has to match; the certificate chain isn't verified in that case
+// unless a caFile is given as well.
+func NewTLSConfig(caFile, fingerprint string) (*tls.Config, error) {
+	config := &tls.Config{}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if fingerprint == "" {
+		return config, nil
+	}
+
+	pin, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+	if err != nil || len(pin) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 fingerprint: %s", fingerprint)
+	}
+
+	// The pin replaces the hostname and chain verification for self-signed certificates
+	verifyChain := caFile != ""
+	roots := config.RootCAs
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no certificate presented")
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		if !bytes.Equal(sum[:], pin) {
+			return fmt.Errorf("%w: got %x", ErrCertificateMismatch, sum)
+		}
+		if !verifyChain {
+			return nil
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("could not parse certificate: %w", err)
+		}
+		intermediates := x509.NewCertPool()
+		for _, raw := range rawCerts[1:] {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("could not parse certificate: %w", err)
+			}
+			intermediates.AddCert(cert)
+		}
+		_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+	return config, nil
+}
+
+// enableTLS discovers the security port of the TR-064 tree and sends
+// all further SOAP calls of the tree to the TLS endpoint.
+func (r *Root) enableTLS(ctx context.Context, device string) error {
+	service, ok := r.Service(SourceTR64, deviceInfoService)
+	if !ok {
+		return fmt.Errorf("could not find service %s", deviceInfoService)
+	}
+	var action *Action
+	for _, name := range securityPortActions {
+		if action, ok = service.Actions[name]; ok {
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("could not find action %s", securityPortActions[0])
+	}
+
+	res, err := action.CallContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get security port: %w", err)
+	}
+	val, ok := action.Output(res, securityPortArg)
+	if !ok {
+		return fmt.Errorf("%s returned no %s", action.Name, securityPortArg)
+	}
+	port, ok := val.(uint64)
+	if !ok || port == 0 || port > 65535 {
+		return fmt.Errorf("invalid security port: %v", val)
+	}
+
+	r.SecureBaseURL = fmt.Sprintf("https://%s:%d", device, port)
+	return nil
+}
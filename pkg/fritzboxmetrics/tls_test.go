@@ -0,0 +1,133 @@
+package fritzboxmetrics
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePEM writes the DER encoded certificate as PEM file
+func writePEM(t *testing.T, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// otherCA creates a self-signed CA certificate which didn't sign the test server's certificate
+func otherCA(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Other CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	cert := s.Certificate().Raw
+	sum := sha256.Sum256(cert)
+	pin := fmt.Sprintf("% X", sum[:])
+	pin = strings.ReplaceAll(pin, " ", ":")
+	wrongPin := strings.Repeat("00", sha256.Size)
+
+	trusted := writePEM(t, cert)
+	untrusted := writePEM(t, otherCA(t))
+
+	for _, tc := range []struct {
+		name        string
+		caFile      string
+		fingerprint string
+		wantErr     bool
+	}{
+		{name: "matching pin", fingerprint: pin},
+		{name: "matching pin without colons", fingerprint: fmt.Sprintf("%x", sum)},
+		{name: "mismatched pin", fingerprint: wrongPin, wantErr: true},
+		{name: "trusted CA", caFile: trusted},
+		{name: "untrusted CA", caFile: untrusted, wantErr: true},
+		{name: "matching pin and trusted CA", caFile: trusted, fingerprint: pin},
+		{name: "matching pin and untrusted CA", caFile: untrusted, fingerprint: pin, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := NewTLSConfig(tc.caFile, tc.fingerprint)
+			if err != nil {
+				t.Fatalf("NewTLSConfig: %v", err)
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+			resp, err := client.Get(s.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.fingerprint == wrongPin && !errors.Is(err, ErrCertificateMismatch) {
+				t.Errorf("got %v, want ErrCertificateMismatch", err)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigInvalid(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := ioutil.WriteFile(notPEM, []byte("no certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct{ caFile, fingerprint string }{
+		{caFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{caFile: notPEM},
+		{fingerprint: "AB:CD"},
+		{fingerprint: strings.Repeat("zz", sha256.Size)},
+	} {
+		if _, err := NewTLSConfig(tc.caFile, tc.fingerprint); err == nil {
+			t.Errorf("NewTLSConfig(%q, %q) succeeded, want an error", tc.caFile, tc.fingerprint)
+		}
+	}
+}
@@ -0,0 +1,2636 @@
+package fritzboxtest
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Documents returns the description documents of a FRITZ!Box 7590 served by
+// default, indexed by their path. The map is a copy and may be modified.
+func Documents() map[string]string {
+	return map[string]string{
+		"/igddesc.xml":            igddesc,
+		"/tr64desc.xml":           tr64desc,
+		"/igdicfgSCPD.xml":        igdicfgSCPD,
+		"/igdconnSCPD.xml":        igdconnSCPD,
+		"/deviceinfoSCPD.xml":     deviceinfoSCPD,
+		"/hostsSCPD.xml":          hostsSCPD,
+		"/wlanconfigSCPD.xml":     wlanconfigSCPD,
+		"/x_homeautoSCPD.xml":     homeautoSCPD,
+		"/wandslifconfigSCPD.xml": wandslifconfigSCPD,
+		"/ethifconfigSCPD.xml":    ethifconfigSCPD,
+		"/userifSCPD.xml":         userifSCPD,
+		"/x_voipSCPD.xml":         voipSCPD,
+	}
+}
+
+const igddesc = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<device>
+<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+<friendlyName>FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM Berlin</manufacturer>
+<manufacturerURL>http://www.avm.de</manufacturerURL>
+<modelDescription>FRITZ!Box 7590</modelDescription>
+<modelName>FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>http://www.avm.de</modelURL>
+<UDN>uuid:75802409-bccb-40e7-8e6c-3431C4000001</UDN>
+<serviceList>
+</serviceList>
+<deviceList>
+<device>
+<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+<friendlyName>WANDevice - FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM Berlin</manufacturer>
+<manufacturerURL>www.avm.de</manufacturerURL>
+<modelDescription>WANDevice - FRITZ!Box 7590</modelDescription>
+<modelName>WANDevice - FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>www.avm.de</modelURL>
+<UDN>uuid:76802409-bccb-40e7-8e6b-3431C4000001</UDN>
+<serviceList>
+<service>
+<serviceType>urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1</serviceType>
+<serviceId>urn:upnp-org:serviceId:WANCommonIFC1</serviceId>
+<controlURL>/igdupnp/control/WANCommonIFC1</controlURL>
+<eventSubURL>/igdupnp/control/WANCommonIFC1</eventSubURL>
+<SCPDURL>/igdicfgSCPD.xml</SCPDURL>
+</service>
+</serviceList>
+<deviceList>
+<device>
+<deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+<friendlyName>WANConnectionDevice - FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM Berlin</manufacturer>
+<manufacturerURL>www.avm.de</manufacturerURL>
+<modelDescription>WANConnectionDevice - FRITZ!Box 7590</modelDescription>
+<modelName>WANConnectionDevice - FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>www.avm.de</modelURL>
+<UDN>uuid:76802409-bccb-40e7-8e6a-3431C4000001</UDN>
+<serviceList>
+<service>
+<serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+<serviceId>urn:upnp-org:serviceId:WANIPConn1</serviceId>
+<controlURL>/igdupnp/control/WANIPConn1</controlURL>
+<eventSubURL>/igdupnp/control/WANIPConn1</eventSubURL>
+<SCPDURL>/igdconnSCPD.xml</SCPDURL>
+</service>
+</serviceList>
+</device>
+</deviceList>
+</device>
+</deviceList>
+<presentationURL>http://fritz.box</presentationURL>
+</device>
+</root>
+`
+
+const tr64desc = `<?xml version="1.0"?>
+<root xmlns="urn:dslforum-org:device-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<systemVersion>
+<HW>226</HW>
+<Major>154</Major>
+<Minor>7</Minor>
+<Patch>29</Patch>
+<Buildnumber>96742</Buildnumber>
+<Display>154.07.29</Display>
+</systemVersion>
+<device>
+<deviceType>urn:dslforum-org:device:InternetGatewayDevice:1</deviceType>
+<friendlyName>FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM</manufacturer>
+<manufacturerURL>www.avm.de</manufacturerURL>
+<modelDescription>FRITZ!Box 7590</modelDescription>
+<modelName>FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>www.avm.de</modelURL>
+<UDN>uuid:739f2409-bccb-40e7-8e6c-3431C4000001</UDN>
+<iconList>
+</iconList>
+<serviceList>
+<service>
+<serviceType>urn:dslforum-org:service:DeviceInfo:1</serviceType>
+<serviceId>urn:DeviceInfo-com:serviceId:DeviceInfo1</serviceId>
+<controlURL>/upnp/control/deviceinfo</controlURL>
+<eventSubURL>/upnp/control/deviceinfo</eventSubURL>
+<SCPDURL>/deviceinfoSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:Hosts:1</serviceType>
+<serviceId>urn:LanDeviceHosts-com:serviceId:Hosts1</serviceId>
+<controlURL>/upnp/control/hosts</controlURL>
+<eventSubURL>/upnp/control/hosts</eventSubURL>
+<SCPDURL>/hostsSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:X_AVM-DE_Homeauto:1</serviceType>
+<serviceId>urn:X_AVM-DE_Homeauto-com:serviceId:X_AVM-DE_Homeauto1</serviceId>
+<controlURL>/upnp/control/x_homeauto</controlURL>
+<eventSubURL>/upnp/control/x_homeauto</eventSubURL>
+<SCPDURL>/x_homeautoSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:UserInterface:1</serviceType>
+<serviceId>urn:UserInterface-com:serviceId:UserInterface1</serviceId>
+<controlURL>/upnp/control/userif</controlURL>
+<eventSubURL>/upnp/control/userif</eventSubURL>
+<SCPDURL>/userifSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:X_VoIP:1</serviceType>
+<serviceId>urn:X_VoIP-com:serviceId:X_VoIP1</serviceId>
+<controlURL>/upnp/control/x_voip</controlURL>
+<eventSubURL>/upnp/control/x_voip</eventSubURL>
+<SCPDURL>/x_voipSCPD.xml</SCPDURL>
+</service>
+</serviceList>
+<deviceList>
+<device>
+<deviceType>urn:dslforum-org:device:LANDevice:1</deviceType>
+<friendlyName>FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM</manufacturer>
+<manufacturerURL>www.avm.de</manufacturerURL>
+<modelDescription>FRITZ!Box 7590</modelDescription>
+<modelName>FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>www.avm.de</modelURL>
+<UDN>uuid:739f2409-bccb-40e7-8e6d-3431C4000001</UDN>
+<serviceList>
+<service>
+<serviceType>urn:dslforum-org:service:WLANConfiguration:1</serviceType>
+<serviceId>urn:WLANConfiguration-com:serviceId:WLANConfiguration1</serviceId>
+<controlURL>/upnp/control/wlanconfig1</controlURL>
+<eventSubURL>/upnp/control/wlanconfig1</eventSubURL>
+<SCPDURL>/wlanconfigSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:WLANConfiguration:1</serviceType>
+<serviceId>urn:WLANConfiguration-com:serviceId:WLANConfiguration2</serviceId>
+<controlURL>/upnp/control/wlanconfig2</controlURL>
+<eventSubURL>/upnp/control/wlanconfig2</eventSubURL>
+<SCPDURL>/wlanconfigSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:WLANConfiguration:1</serviceType>
+<serviceId>urn:WLANConfiguration-com:serviceId:WLANConfiguration3</serviceId>
+<controlURL>/upnp/control/wlanconfig3</controlURL>
+<eventSubURL>/upnp/control/wlanconfig3</eventSubURL>
+<SCPDURL>/wlanconfigSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:LANEthernetInterfaceConfig:1</serviceType>
+<serviceId>urn:LANEthernetIfCfg-com:serviceId:LANEthernetInterfaceConfig1</serviceId>
+<controlURL>/upnp/control/lanethernetifcfg1</controlURL>
+<eventSubURL>/upnp/control/lanethernetifcfg1</eventSubURL>
+<SCPDURL>/ethifconfigSCPD.xml</SCPDURL>
+</service>
+<service>
+<serviceType>urn:dslforum-org:service:LANEthernetInterfaceConfig:1</serviceType>
+<serviceId>urn:LANEthernetIfCfg-com:serviceId:LANEthernetInterfaceConfig2</serviceId>
+<controlURL>/upnp/control/lanethernetifcfg2</controlURL>
+<eventSubURL>/upnp/control/lanethernetifcfg2</eventSubURL>
+<SCPDURL>/ethifconfigSCPD.xml</SCPDURL>
+</service>
+</serviceList>
+</device>
+<device>
+<deviceType>urn:dslforum-org:device:WANDevice:1</deviceType>
+<friendlyName>FRITZ!Box 7590</friendlyName>
+<manufacturer>AVM</manufacturer>
+<manufacturerURL>www.avm.de</manufacturerURL>
+<modelDescription>FRITZ!Box 7590</modelDescription>
+<modelName>FRITZ!Box 7590</modelName>
+<modelNumber>avm</modelNumber>
+<modelURL>www.avm.de</modelURL>
+<UDN>uuid:739f2409-bccb-40e7-8e6e-3431C4000001</UDN>
+<serviceList>
+<service>
+<serviceType>urn:dslforum-org:service:WANDSLInterfaceConfig:1</serviceType>
+<serviceId>urn:WANDSLIfConfig-com:serviceId:WANDSLInterfaceConfig1</serviceId>
+<controlURL>/upnp/control/wandslifconfig1</controlURL>
+<eventSubURL>/upnp/control/wandslifconfig1</eventSubURL>
+<SCPDURL>/wandslifconfigSCPD.xml</SCPDURL>
+</service>
+</serviceList>
+</device>
+</deviceList>
+<presentationURL>http://fritz.box</presentationURL>
+</device>
+</root>
+`
+
+const igdicfgSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetCommonLinkProperties</name>
+<argumentList>
+<argument>
+<name>NewWANAccessType</name>
+<direction>out</direction>
+<relatedStateVariable>WANAccessType</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLayer1UpstreamMaxBitRate</name>
+<direction>out</direction>
+<relatedStateVariable>Layer1UpstreamMaxBitRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLayer1DownstreamMaxBitRate</name>
+<direction>out</direction>
+<relatedStateVariable>Layer1DownstreamMaxBitRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPhysicalLinkStatus</name>
+<direction>out</direction>
+<relatedStateVariable>PhysicalLinkStatus</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetTotalBytesSent</name>
+<argumentList>
+<argument>
+<name>NewTotalBytesSent</name>
+<direction>out</direction>
+<relatedStateVariable>TotalBytesSent</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetTotalBytesReceived</name>
+<argumentList>
+<argument>
+<name>NewTotalBytesReceived</name>
+<direction>out</direction>
+<relatedStateVariable>TotalBytesReceived</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetTotalPacketsSent</name>
+<argumentList>
+<argument>
+<name>NewTotalPacketsSent</name>
+<direction>out</direction>
+<relatedStateVariable>TotalPacketsSent</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetTotalPacketsReceived</name>
+<argumentList>
+<argument>
+<name>NewTotalPacketsReceived</name>
+<direction>out</direction>
+<relatedStateVariable>TotalPacketsReceived</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetAddonInfos</name>
+<argumentList>
+<argument>
+<name>NewByteSendRate</name>
+<direction>out</direction>
+<relatedStateVariable>ByteSendRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewByteReceiveRate</name>
+<direction>out</direction>
+<relatedStateVariable>ByteReceiveRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPacketSendRate</name>
+<direction>out</direction>
+<relatedStateVariable>PacketSendRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPacketReceiveRate</name>
+<direction>out</direction>
+<relatedStateVariable>PacketReceiveRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTotalBytesSent</name>
+<direction>out</direction>
+<relatedStateVariable>TotalBytesSent</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTotalBytesReceived</name>
+<direction>out</direction>
+<relatedStateVariable>TotalBytesReceived</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAutoDisconnectTime</name>
+<direction>out</direction>
+<relatedStateVariable>AutoDisconnectTime</relatedStateVariable>
+</argument>
+<argument>
+<name>NewIdleDisconnectTime</name>
+<direction>out</direction>
+<relatedStateVariable>IdleDisconnectTime</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDNSServer1</name>
+<direction>out</direction>
+<relatedStateVariable>DNSServer1</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDNSServer2</name>
+<direction>out</direction>
+<relatedStateVariable>DNSServer2</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoipDNSServer1</name>
+<direction>out</direction>
+<relatedStateVariable>VoipDNSServer1</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoipDNSServer2</name>
+<direction>out</direction>
+<relatedStateVariable>VoipDNSServer2</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpnpControlEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>UpnpControlEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewRoutedBridgedModeBoth</name>
+<direction>out</direction>
+<relatedStateVariable>RoutedBridgedModeBoth</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM_DE_TotalBytesSent64</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM_DE_TotalBytesSent64</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM_DE_TotalBytesReceived64</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM_DE_TotalBytesReceived64</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM_DE_WANAccessType</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM_DE_WANAccessType</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>WANAccessType</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DSL</allowedValue>
+<allowedValue>POTS</allowedValue>
+<allowedValue>Cable</allowedValue>
+<allowedValue>Ethernet</allowedValue>
+<allowedValue>Other</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Layer1UpstreamMaxBitRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Layer1DownstreamMaxBitRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="yes">
+<name>PhysicalLinkStatus</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Up</allowedValue>
+<allowedValue>Down</allowedValue>
+<allowedValue>Initializing</allowedValue>
+<allowedValue>Unavailable</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalBytesSent</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalBytesReceived</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalPacketsSent</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalPacketsReceived</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ByteSendRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ByteReceiveRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>PacketSendRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>PacketReceiveRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AutoDisconnectTime</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>IdleDisconnectTime</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DNSServer1</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DNSServer2</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoipDNSServer1</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoipDNSServer2</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpnpControlEnabled</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>RoutedBridgedModeBoth</name>
+<dataType>ui1</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM_DE_TotalBytesSent64</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM_DE_TotalBytesReceived64</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM_DE_WANAccessType</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const igdconnSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetStatusInfo</name>
+<argumentList>
+<argument>
+<name>NewConnectionStatus</name>
+<direction>out</direction>
+<relatedStateVariable>ConnectionStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLastConnectionError</name>
+<direction>out</direction>
+<relatedStateVariable>LastConnectionError</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUptime</name>
+<direction>out</direction>
+<relatedStateVariable>Uptime</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetExternalIPAddress</name>
+<argumentList>
+<argument>
+<name>NewExternalIPAddress</name>
+<direction>out</direction>
+<relatedStateVariable>ExternalIPAddress</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="yes">
+<name>ConnectionStatus</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Unconfigured</allowedValue>
+<allowedValue>Connecting</allowedValue>
+<allowedValue>Authenticating</allowedValue>
+<allowedValue>PendingDisconnect</allowedValue>
+<allowedValue>Disconnecting</allowedValue>
+<allowedValue>Disconnected</allowedValue>
+<allowedValue>Connected</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>LastConnectionError</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>ERROR_NONE</allowedValue>
+<allowedValue>ERROR_ISP_TIME_OUT</allowedValue>
+<allowedValue>ERROR_COMMAND_ABORTED</allowedValue>
+<allowedValue>ERROR_NOT_ENABLED_FOR_INTERNET</allowedValue>
+<allowedValue>ERROR_BAD_PHONE_NUMBER</allowedValue>
+<allowedValue>ERROR_USER_DISCONNECT</allowedValue>
+<allowedValue>ERROR_ISP_DISCONNECT</allowedValue>
+<allowedValue>ERROR_IDLE_DISCONNECT</allowedValue>
+<allowedValue>ERROR_FORCED_DISCONNECT</allowedValue>
+<allowedValue>ERROR_SERVER_OUT_OF_RESOURCES</allowedValue>
+<allowedValue>ERROR_RESTRICTED_LOGON_HOURS</allowedValue>
+<allowedValue>ERROR_ACCOUNT_DISABLED</allowedValue>
+<allowedValue>ERROR_ACCOUNT_EXPIRED</allowedValue>
+<allowedValue>ERROR_PASSWORD_EXPIRED</allowedValue>
+<allowedValue>ERROR_AUTHENTICATION_FAILURE</allowedValue>
+<allowedValue>ERROR_NO_DIALTONE</allowedValue>
+<allowedValue>ERROR_NO_CARRIER</allowedValue>
+<allowedValue>ERROR_NO_ANSWER</allowedValue>
+<allowedValue>ERROR_LINE_BUSY</allowedValue>
+<allowedValue>ERROR_UNSUPPORTED_BITSPERSECOND</allowedValue>
+<allowedValue>ERROR_TOO_MANY_LINE_ERRORS</allowedValue>
+<allowedValue>ERROR_IP_CONFIGURATION</allowedValue>
+<allowedValue>ERROR_UNKNOWN</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Uptime</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="yes">
+<name>ExternalIPAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const deviceinfoSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewManufacturerName</name>
+<direction>out</direction>
+<relatedStateVariable>ManufacturerName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewManufacturerOUI</name>
+<direction>out</direction>
+<relatedStateVariable>ManufacturerOUI</relatedStateVariable>
+</argument>
+<argument>
+<name>NewModelName</name>
+<direction>out</direction>
+<relatedStateVariable>ModelName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDescription</name>
+<direction>out</direction>
+<relatedStateVariable>Description</relatedStateVariable>
+</argument>
+<argument>
+<name>NewProductClass</name>
+<direction>out</direction>
+<relatedStateVariable>ProductClass</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSerialNumber</name>
+<direction>out</direction>
+<relatedStateVariable>SerialNumber</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSoftwareVersion</name>
+<direction>out</direction>
+<relatedStateVariable>SoftwareVersion</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHardwareVersion</name>
+<direction>out</direction>
+<relatedStateVariable>HardwareVersion</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSpecVersion</name>
+<direction>out</direction>
+<relatedStateVariable>SpecVersion</relatedStateVariable>
+</argument>
+<argument>
+<name>NewProvisioningCode</name>
+<direction>out</direction>
+<relatedStateVariable>ProvisioningCode</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpTime</name>
+<direction>out</direction>
+<relatedStateVariable>UpTime</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceLog</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceLog</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>SetProvisioningCode</name>
+<argumentList>
+<argument>
+<name>NewProvisioningCode</name>
+<direction>in</direction>
+<relatedStateVariable>ProvisioningCode</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetDeviceLog</name>
+<argumentList>
+<argument>
+<name>NewDeviceLog</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceLog</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetSecurityPort</name>
+<argumentList>
+<argument>
+<name>NewSecurityPort</name>
+<direction>out</direction>
+<relatedStateVariable>SecurityPort</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>ManufacturerName</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ManufacturerOUI</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ModelName</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Description</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ProductClass</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SerialNumber</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SoftwareVersion</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HardwareVersion</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SpecVersion</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ProvisioningCode</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpTime</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DeviceLog</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SecurityPort</name>
+<dataType>ui2</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const hostsSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetHostNumberOfEntries</name>
+<argumentList>
+<argument>
+<name>NewHostNumberOfEntries</name>
+<direction>out</direction>
+<relatedStateVariable>HostNumberOfEntries</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetSpecificHostEntry</name>
+<argumentList>
+<argument>
+<name>NewMACAddress</name>
+<direction>in</direction>
+<relatedStateVariable>MACAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewIPAddress</name>
+<direction>out</direction>
+<relatedStateVariable>IPAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAddressSource</name>
+<direction>out</direction>
+<relatedStateVariable>AddressSource</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLeaseTimeRemaining</name>
+<direction>out</direction>
+<relatedStateVariable>LeaseTimeRemaining</relatedStateVariable>
+</argument>
+<argument>
+<name>NewInterfaceType</name>
+<direction>out</direction>
+<relatedStateVariable>InterfaceType</relatedStateVariable>
+</argument>
+<argument>
+<name>NewActive</name>
+<direction>out</direction>
+<relatedStateVariable>Active</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHostName</name>
+<direction>out</direction>
+<relatedStateVariable>HostName</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetGenericHostEntry</name>
+<argumentList>
+<argument>
+<name>NewIndex</name>
+<direction>in</direction>
+<relatedStateVariable>Index</relatedStateVariable>
+</argument>
+<argument>
+<name>NewIPAddress</name>
+<direction>out</direction>
+<relatedStateVariable>IPAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAddressSource</name>
+<direction>out</direction>
+<relatedStateVariable>AddressSource</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLeaseTimeRemaining</name>
+<direction>out</direction>
+<relatedStateVariable>LeaseTimeRemaining</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMACAddress</name>
+<direction>out</direction>
+<relatedStateVariable>MACAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewInterfaceType</name>
+<direction>out</direction>
+<relatedStateVariable>InterfaceType</relatedStateVariable>
+</argument>
+<argument>
+<name>NewActive</name>
+<direction>out</direction>
+<relatedStateVariable>Active</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHostName</name>
+<direction>out</direction>
+<relatedStateVariable>HostName</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetHostListPath</name>
+<argumentList>
+<argument>
+<name>NewX_AVM-DE_HostListPath</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_HostListPath</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetMeshListPath</name>
+<argumentList>
+<argument>
+<name>NewX_AVM-DE_MeshListPath</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_MeshListPath</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>HostNumberOfEntries</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>IPAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AddressSource</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DHCP</allowedValue>
+<allowedValue>Static</allowedValue>
+<allowedValue>AutoIP</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>LeaseTimeRemaining</name>
+<dataType>i4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MACAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>InterfaceType</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Ethernet</allowedValue>
+<allowedValue>802.11</allowedValue>
+<allowedValue>HomePlug</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Active</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HostName</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Index</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_HostListPath</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_MeshListPath</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const wlanconfigSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewEnable</name>
+<direction>out</direction>
+<relatedStateVariable>Enable</relatedStateVariable>
+</argument>
+<argument>
+<name>NewStatus</name>
+<direction>out</direction>
+<relatedStateVariable>Status</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMaxBitRate</name>
+<direction>out</direction>
+<relatedStateVariable>MaxBitRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewChannel</name>
+<direction>out</direction>
+<relatedStateVariable>Channel</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSSID</name>
+<direction>out</direction>
+<relatedStateVariable>SSID</relatedStateVariable>
+</argument>
+<argument>
+<name>NewBeaconType</name>
+<direction>out</direction>
+<relatedStateVariable>BeaconType</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMACAddressControlEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>MACAddressControlEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewStandard</name>
+<direction>out</direction>
+<relatedStateVariable>Standard</relatedStateVariable>
+</argument>
+<argument>
+<name>NewBSSID</name>
+<direction>out</direction>
+<relatedStateVariable>BSSID</relatedStateVariable>
+</argument>
+<argument>
+<name>NewBasicEncryptionModes</name>
+<direction>out</direction>
+<relatedStateVariable>BasicEncryptionModes</relatedStateVariable>
+</argument>
+<argument>
+<name>NewBasicAuthenticationMode</name>
+<direction>out</direction>
+<relatedStateVariable>BasicAuthenticationMode</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetTotalAssociations</name>
+<argumentList>
+<argument>
+<name>NewTotalAssociations</name>
+<direction>out</direction>
+<relatedStateVariable>TotalAssociations</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetGenericAssociatedDeviceInfo</name>
+<argumentList>
+<argument>
+<name>NewAssociatedDeviceIndex</name>
+<direction>in</direction>
+<relatedStateVariable>AssociatedDeviceIndex</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAssociatedDeviceMACAddress</name>
+<direction>out</direction>
+<relatedStateVariable>AssociatedDeviceMACAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAssociatedDeviceIPAddress</name>
+<direction>out</direction>
+<relatedStateVariable>AssociatedDeviceIPAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAssociatedDeviceAuthState</name>
+<direction>out</direction>
+<relatedStateVariable>AssociatedDeviceAuthState</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_Speed</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_Speed</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_SignalStrength</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_SignalStrength</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetWLANDeviceListPath</name>
+<argumentList>
+<argument>
+<name>NewX_AVM-DE_WLANDeviceListPath</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_WLANDeviceListPath</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>Enable</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Status</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Up</allowedValue>
+<allowedValue>Error</allowedValue>
+<allowedValue>Disabled</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MaxBitRate</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Channel</name>
+<dataType>ui1</dataType>
+<allowedValueRange>
+<minimum>0</minimum>
+<maximum>165</maximum>
+<step>1</step>
+</allowedValueRange>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SSID</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>BeaconType</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MACAddressControlEnabled</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Standard</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>a</allowedValue>
+<allowedValue>b</allowedValue>
+<allowedValue>g</allowedValue>
+<allowedValue>n</allowedValue>
+<allowedValue>ac</allowedValue>
+<allowedValue>ax</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>BSSID</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>BasicEncryptionModes</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>BasicAuthenticationMode</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalAssociations</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AssociatedDeviceIndex</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AssociatedDeviceMACAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AssociatedDeviceIPAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AssociatedDeviceAuthState</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_Speed</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_SignalStrength</name>
+<dataType>ui1</dataType>
+<allowedValueRange>
+<minimum>0</minimum>
+<maximum>100</maximum>
+<step>1</step>
+</allowedValueRange>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_WLANDeviceListPath</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const homeautoSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewAllowedCharsAIN</name>
+<direction>out</direction>
+<relatedStateVariable>AllowedCharsAIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMaxCharsAIN</name>
+<direction>out</direction>
+<relatedStateVariable>MaxCharsAIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMinCharsAIN</name>
+<direction>out</direction>
+<relatedStateVariable>MinCharsAIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMaxCharsDeviceName</name>
+<direction>out</direction>
+<relatedStateVariable>MaxCharsDeviceName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMinCharsDeviceName</name>
+<direction>out</direction>
+<relatedStateVariable>MinCharsDeviceName</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetGenericDeviceInfos</name>
+<argumentList>
+<argument>
+<name>NewIndex</name>
+<direction>in</direction>
+<relatedStateVariable>Index</relatedStateVariable>
+</argument>
+<argument>
+<name>NewAIN</name>
+<direction>out</direction>
+<relatedStateVariable>AIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceId</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceId</relatedStateVariable>
+</argument>
+<argument>
+<name>NewFunctionBitMask</name>
+<direction>out</direction>
+<relatedStateVariable>FunctionBitMask</relatedStateVariable>
+</argument>
+<argument>
+<name>NewFirmwareVersion</name>
+<direction>out</direction>
+<relatedStateVariable>FirmwareVersion</relatedStateVariable>
+</argument>
+<argument>
+<name>NewManufacturer</name>
+<direction>out</direction>
+<relatedStateVariable>Manufacturer</relatedStateVariable>
+</argument>
+<argument>
+<name>NewProductName</name>
+<direction>out</direction>
+<relatedStateVariable>ProductName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceName</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPresent</name>
+<direction>out</direction>
+<relatedStateVariable>Present</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterPower</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterPower</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterEnergy</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterEnergy</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureCelsius</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureCelsius</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureOffset</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureOffset</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchState</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchState</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchMode</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchMode</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchLock</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchLock</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrSetVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrSetVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrSetTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrSetTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrReduceVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrReduceVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrReduceTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrReduceTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrComfortVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrComfortVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrComfortTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrComfortTemperature</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetSpecificDeviceInfos</name>
+<argumentList>
+<argument>
+<name>NewAIN</name>
+<direction>in</direction>
+<relatedStateVariable>AIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceId</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceId</relatedStateVariable>
+</argument>
+<argument>
+<name>NewFunctionBitMask</name>
+<direction>out</direction>
+<relatedStateVariable>FunctionBitMask</relatedStateVariable>
+</argument>
+<argument>
+<name>NewFirmwareVersion</name>
+<direction>out</direction>
+<relatedStateVariable>FirmwareVersion</relatedStateVariable>
+</argument>
+<argument>
+<name>NewManufacturer</name>
+<direction>out</direction>
+<relatedStateVariable>Manufacturer</relatedStateVariable>
+</argument>
+<argument>
+<name>NewProductName</name>
+<direction>out</direction>
+<relatedStateVariable>ProductName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceName</name>
+<direction>out</direction>
+<relatedStateVariable>DeviceName</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPresent</name>
+<direction>out</direction>
+<relatedStateVariable>Present</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterPower</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterPower</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMultimeterEnergy</name>
+<direction>out</direction>
+<relatedStateVariable>MultimeterEnergy</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureCelsius</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureCelsius</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTemperatureOffset</name>
+<direction>out</direction>
+<relatedStateVariable>TemperatureOffset</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchState</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchState</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchMode</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchMode</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchLock</name>
+<direction>out</direction>
+<relatedStateVariable>SwitchLock</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsEnabled</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsEnabled</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsValid</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsValid</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrIsTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrIsTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrSetVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrSetVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrSetTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrSetTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrReduceVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrReduceVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrReduceTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrReduceTemperature</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrComfortVentilStatus</name>
+<direction>out</direction>
+<relatedStateVariable>HkrComfortVentilStatus</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHkrComfortTemperature</name>
+<direction>out</direction>
+<relatedStateVariable>HkrComfortTemperature</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>SetDeviceName</name>
+<argumentList>
+<argument>
+<name>NewAIN</name>
+<direction>in</direction>
+<relatedStateVariable>AIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDeviceName</name>
+<direction>in</direction>
+<relatedStateVariable>DeviceName</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>SetSwitch</name>
+<argumentList>
+<argument>
+<name>NewAIN</name>
+<direction>in</direction>
+<relatedStateVariable>AIN</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSwitchState</name>
+<direction>in</direction>
+<relatedStateVariable>SwitchState</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>AllowedCharsAIN</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MaxCharsAIN</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MinCharsAIN</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MaxCharsDeviceName</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MinCharsDeviceName</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Index</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>AIN</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DeviceId</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>FunctionBitMask</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>FirmwareVersion</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Manufacturer</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ProductName</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DeviceName</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Present</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DISCONNECTED</allowedValue>
+<allowedValue>REGISTERED</allowedValue>
+<allowedValue>CONNECTED</allowedValue>
+<allowedValue>UNKNOWN</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MultimeterIsEnabled</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DISABLED</allowedValue>
+<allowedValue>ENABLED</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MultimeterIsValid</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>INVALID</allowedValue>
+<allowedValue>VALID</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MultimeterPower</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MultimeterEnergy</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TemperatureIsEnabled</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DISABLED</allowedValue>
+<allowedValue>ENABLED</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TemperatureIsValid</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>INVALID</allowedValue>
+<allowedValue>VALID</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TemperatureCelsius</name>
+<dataType>i4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TemperatureOffset</name>
+<dataType>i4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SwitchIsEnabled</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DISABLED</allowedValue>
+<allowedValue>ENABLED</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SwitchIsValid</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>INVALID</allowedValue>
+<allowedValue>VALID</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SwitchState</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>OFF</allowedValue>
+<allowedValue>ON</allowedValue>
+<allowedValue>TOGGLE</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SwitchMode</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>AUTO</allowedValue>
+<allowedValue>MANUAL</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>SwitchLock</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrIsEnabled</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>DISABLED</allowedValue>
+<allowedValue>ENABLED</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrIsValid</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>INVALID</allowedValue>
+<allowedValue>VALID</allowedValue>
+<allowedValue>UNDEFINED</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrIsTemperature</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrSetVentilStatus</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>CLOSED</allowedValue>
+<allowedValue>OPEN</allowedValue>
+<allowedValue>TEMP</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrSetTemperature</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrReduceVentilStatus</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>CLOSED</allowedValue>
+<allowedValue>OPEN</allowedValue>
+<allowedValue>TEMP</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrReduceTemperature</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrComfortVentilStatus</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>CLOSED</allowedValue>
+<allowedValue>OPEN</allowedValue>
+<allowedValue>TEMP</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>HkrComfortTemperature</name>
+<dataType>ui4</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const wandslifconfigSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewEnable</name>
+<direction>out</direction>
+<relatedStateVariable>Enable</relatedStateVariable>
+</argument>
+<argument>
+<name>NewStatus</name>
+<direction>out</direction>
+<relatedStateVariable>Status</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDataPath</name>
+<direction>out</direction>
+<relatedStateVariable>DataPath</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpstreamCurrRate</name>
+<direction>out</direction>
+<relatedStateVariable>UpstreamCurrRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDownstreamCurrRate</name>
+<direction>out</direction>
+<relatedStateVariable>DownstreamCurrRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpstreamMaxRate</name>
+<direction>out</direction>
+<relatedStateVariable>UpstreamMaxRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDownstreamMaxRate</name>
+<direction>out</direction>
+<relatedStateVariable>DownstreamMaxRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpstreamNoiseMargin</name>
+<direction>out</direction>
+<relatedStateVariable>UpstreamNoiseMargin</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDownstreamNoiseMargin</name>
+<direction>out</direction>
+<relatedStateVariable>DownstreamNoiseMargin</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpstreamAttenuation</name>
+<direction>out</direction>
+<relatedStateVariable>UpstreamAttenuation</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDownstreamAttenuation</name>
+<direction>out</direction>
+<relatedStateVariable>DownstreamAttenuation</relatedStateVariable>
+</argument>
+<argument>
+<name>NewATURVendor</name>
+<direction>out</direction>
+<relatedStateVariable>ATURVendor</relatedStateVariable>
+</argument>
+<argument>
+<name>NewATURCountry</name>
+<direction>out</direction>
+<relatedStateVariable>ATURCountry</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUpstreamPower</name>
+<direction>out</direction>
+<relatedStateVariable>UpstreamPower</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDownstreamPower</name>
+<direction>out</direction>
+<relatedStateVariable>DownstreamPower</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetStatisticsTotal</name>
+<argumentList>
+<argument>
+<name>NewReceiveBlocks</name>
+<direction>out</direction>
+<relatedStateVariable>TotalReceiveBlocks</relatedStateVariable>
+</argument>
+<argument>
+<name>NewTransmitBlocks</name>
+<direction>out</direction>
+<relatedStateVariable>TotalTransmitBlocks</relatedStateVariable>
+</argument>
+<argument>
+<name>NewCellDelin</name>
+<direction>out</direction>
+<relatedStateVariable>TotalCellDelin</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLinkRetrain</name>
+<direction>out</direction>
+<relatedStateVariable>TotalLinkRetrain</relatedStateVariable>
+</argument>
+<argument>
+<name>NewInitErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalInitErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewInitTimeouts</name>
+<direction>out</direction>
+<relatedStateVariable>TotalInitTimeouts</relatedStateVariable>
+</argument>
+<argument>
+<name>NewLossOfFraming</name>
+<direction>out</direction>
+<relatedStateVariable>TotalLossOfFraming</relatedStateVariable>
+</argument>
+<argument>
+<name>NewErroredSecs</name>
+<direction>out</direction>
+<relatedStateVariable>TotalErroredSecs</relatedStateVariable>
+</argument>
+<argument>
+<name>NewSeverelyErroredSecs</name>
+<direction>out</direction>
+<relatedStateVariable>TotalSeverelyErroredSecs</relatedStateVariable>
+</argument>
+<argument>
+<name>NewFECErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalFECErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewATUCFECErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalATUCFECErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewHECErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalHECErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewATUCHECErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalATUCHECErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewCRCErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalCRCErrors</relatedStateVariable>
+</argument>
+<argument>
+<name>NewATUCCRCErrors</name>
+<direction>out</direction>
+<relatedStateVariable>TotalATUCCRCErrors</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>Enable</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Status</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Up</allowedValue>
+<allowedValue>Initializing</allowedValue>
+<allowedValue>EstablishingLink</allowedValue>
+<allowedValue>NoSignal</allowedValue>
+<allowedValue>Error</allowedValue>
+<allowedValue>Disabled</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DataPath</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Interleaved</allowedValue>
+<allowedValue>Fast</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpstreamCurrRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DownstreamCurrRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpstreamMaxRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DownstreamMaxRate</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpstreamNoiseMargin</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DownstreamNoiseMargin</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpstreamAttenuation</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DownstreamAttenuation</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ATURVendor</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ATURCountry</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UpstreamPower</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DownstreamPower</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalReceiveBlocks</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalTransmitBlocks</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalCellDelin</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalLinkRetrain</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalInitErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalInitTimeouts</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalLossOfFraming</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalErroredSecs</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalSeverelyErroredSecs</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalFECErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalATUCFECErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalHECErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalATUCHECErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalCRCErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>TotalATUCCRCErrors</name>
+<dataType>ui4</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const ethifconfigSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewEnable</name>
+<direction>out</direction>
+<relatedStateVariable>Enable</relatedStateVariable>
+</argument>
+<argument>
+<name>NewStatus</name>
+<direction>out</direction>
+<relatedStateVariable>Status</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMACAddress</name>
+<direction>out</direction>
+<relatedStateVariable>MACAddress</relatedStateVariable>
+</argument>
+<argument>
+<name>NewMaxBitRate</name>
+<direction>out</direction>
+<relatedStateVariable>MaxBitRate</relatedStateVariable>
+</argument>
+<argument>
+<name>NewDuplexMode</name>
+<direction>out</direction>
+<relatedStateVariable>DuplexMode</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetStatistics</name>
+<argumentList>
+<argument>
+<name>NewBytesSent</name>
+<direction>out</direction>
+<relatedStateVariable>Stats.BytesSent</relatedStateVariable>
+</argument>
+<argument>
+<name>NewBytesReceived</name>
+<direction>out</direction>
+<relatedStateVariable>Stats.BytesReceived</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPacketsSent</name>
+<direction>out</direction>
+<relatedStateVariable>Stats.PacketsSent</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPacketsReceived</name>
+<direction>out</direction>
+<relatedStateVariable>Stats.PacketsReceived</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>Enable</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Status</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Up</allowedValue>
+<allowedValue>NoLink</allowedValue>
+<allowedValue>Error</allowedValue>
+<allowedValue>Disabled</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MACAddress</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>MaxBitRate</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>10</allowedValue>
+<allowedValue>100</allowedValue>
+<allowedValue>1000</allowedValue>
+<allowedValue>Auto</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>DuplexMode</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Half</allowedValue>
+<allowedValue>Full</allowedValue>
+<allowedValue>Auto</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Stats.BytesSent</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Stats.BytesReceived</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Stats.PacketsSent</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>Stats.PacketsReceived</name>
+<dataType>ui4</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const userifSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfo</name>
+<argumentList>
+<argument>
+<name>NewUpgradeAvailable</name>
+<direction>out</direction>
+<relatedStateVariable>UpgradeAvailable</relatedStateVariable>
+</argument>
+<argument>
+<name>NewPasswordRequired</name>
+<direction>out</direction>
+<relatedStateVariable>PasswordRequired</relatedStateVariable>
+</argument>
+<argument>
+<name>NewUserChoice</name>
+<direction>out</direction>
+<relatedStateVariable>UserChoice</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_Public</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_Public</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_Version</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_Version</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_DownloadURL</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_DownloadURL</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_InfoURL</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_InfoURL</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_UpdateState</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_UpdateState</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_LaborVersion</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_LaborVersion</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>UpgradeAvailable</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>PasswordRequired</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>UserChoice</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_Public</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_Version</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_DownloadURL</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_InfoURL</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_UpdateState</name>
+<dataType>string</dataType>
+<allowedValueList>
+<allowedValue>Started</allowedValue>
+<allowedValue>Stopped</allowedValue>
+<allowedValue>Error</allowedValue>
+<allowedValue>None</allowedValue>
+<allowedValue>Unknown</allowedValue>
+</allowedValueList>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_LaborVersion</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+const voipSCPD = `<?xml version="1.0"?>
+<scpd xmlns="urn:dslforum-org:service-1-0">
+<specVersion>
+<major>1</major>
+<minor>0</minor>
+</specVersion>
+<actionList>
+<action>
+<name>GetInfoEx</name>
+<argumentList>
+<argument>
+<name>NewVoIPNumberMinChars</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPNumberMinChars</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPNumberMaxChars</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPNumberMaxChars</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPNumberAllowedChars</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPNumberAllowedChars</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPEnableAreaCode</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPEnableAreaCode</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPEnableCountryCode</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPEnableCountryCode</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>GetExistingVoIPNumbers</name>
+<argumentList>
+<argument>
+<name>NewExistingVoIPNumbers</name>
+<direction>out</direction>
+<relatedStateVariable>ExistingVoIPNumbers</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetNumberOfNumbers</name>
+<argumentList>
+<argument>
+<name>NewNumberOfNumbers</name>
+<direction>out</direction>
+<relatedStateVariable>NumberOfNumbers</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetNumbers</name>
+<argumentList>
+<argument>
+<name>NewNumberList</name>
+<direction>out</direction>
+<relatedStateVariable>NumberList</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetVoIPAccount</name>
+<argumentList>
+<argument>
+<name>NewVoIPAccountIndex</name>
+<direction>in</direction>
+<relatedStateVariable>VoIPAccountIndex</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPRegistrar</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPRegistrar</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPNumber</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPNumber</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPUsername</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPUsername</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPPassword</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPPassword</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPOutboundProxy</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPOutboundProxy</relatedStateVariable>
+</argument>
+<argument>
+<name>NewVoIPSTUNServer</name>
+<direction>out</direction>
+<relatedStateVariable>VoIPSTUNServer</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+<action>
+<name>X_AVM-DE_GetVoIPStatus</name>
+<argumentList>
+<argument>
+<name>NewVoIPAccountIndex</name>
+<direction>in</direction>
+<relatedStateVariable>VoIPAccountIndex</relatedStateVariable>
+</argument>
+<argument>
+<name>NewX_AVM-DE_VoIPStatus</name>
+<direction>out</direction>
+<relatedStateVariable>X_AVM-DE_VoIPStatus</relatedStateVariable>
+</argument>
+</argumentList>
+</action>
+</actionList>
+<serviceStateTable>
+<stateVariable sendEvents="no">
+<name>VoIPNumberMinChars</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPNumberMaxChars</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPNumberAllowedChars</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPEnableAreaCode</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPEnableCountryCode</name>
+<dataType>boolean</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>ExistingVoIPNumbers</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>NumberOfNumbers</name>
+<dataType>ui4</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>NumberList</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPAccountIndex</name>
+<dataType>ui2</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPRegistrar</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPNumber</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPUsername</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPPassword</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPOutboundProxy</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>VoIPSTUNServer</name>
+<dataType>string</dataType>
+</stateVariable>
+<stateVariable sendEvents="no">
+<name>X_AVM-DE_VoIPStatus</name>
+<dataType>string</dataType>
+</stateVariable>
+</serviceStateTable>
+</scpd>
+`
+
+// AHADeviceList is an answer of getdevicelistinfos with a FRITZ!DECT 200 plug
+// and a FRITZ!DECT 301 thermostat, see Server.SetAHAResponse
+const AHADeviceList = `<devicelist version="1" fwversion="7.29">
+<device identifier="08761 0000434" id="17" functionbitmask="35712" fwversion="04.25" manufacturer="AVM" productname="FRITZ!DECT 200">
+<present>1</present>
+<txbusy>0</txbusy>
+<name>Kaffeemaschine</name>
+<switch><state>1</state><mode>manuell</mode><lock>0</lock><devicelock>0</devicelock></switch>
+<simpleonoff><state>1</state></simpleonoff>
+<powermeter><voltage>230051</voltage><power>1243000</power><energy>70710</energy></powermeter>
+<temperature><celsius>225</celsius><offset>0</offset></temperature>
+</device>
+<device identifier="09995 0123456" id="18" functionbitmask="320" fwversion="05.08" manufacturer="AVM" productname="FRITZ!DECT 301">
+<present>1</present>
+<txbusy>0</txbusy>
+<name>Büro</name>
+<battery>80</battery>
+<batterylow>0</batterylow>
+<temperature><celsius>205</celsius><offset>-5</offset></temperature>
+<hkr><tist>41</tist><tsoll>44</tsoll><absenk>32</absenk><komfort>44</komfort><lock>0</lock><devicelock>0</devicelock><errorcode>0</errorcode><windowopenactiv>1</windowopenactiv><windowopenactiveendtime>1602767444</windowopenactiveendtime><boostactive>0</boostactive><boostactiveendtime>0</boostactiveendtime><batterylow>0</batterylow><battery>80</battery></hkr>
+</device>
+</devicelist>`
+
+// HostList is a host list as downloaded from the path X_AVM-DE_GetHostListPath
+// returns, with a wired and a wireless host, see Server.SetDocument
+const HostList = `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<Item>
+<Index>1</Index>
+<IPAddress>192.168.178.20</IPAddress>
+<MACAddress>02:00:00:00:00:01</MACAddress>
+<Active>1</Active>
+<HostName>workstation</HostName>
+<InterfaceType>Ethernet</InterfaceType>
+<X_AVM-DE_Port>1</X_AVM-DE_Port>
+<X_AVM-DE_Speed>1000</X_AVM-DE_Speed>
+<X_AVM-DE_UpdateAvailable>0</X_AVM-DE_UpdateAvailable>
+<X_AVM-DE_Guest>0</X_AVM-DE_Guest>
+<X_AVM-DE_VPN>0</X_AVM-DE_VPN>
+<X_AVM-DE_WANAccess>granted</X_AVM-DE_WANAccess>
+<X_AVM-DE_FriendlyName>workstation</X_AVM-DE_FriendlyName>
+</Item>
+<Item>
+<Index>2</Index>
+<IPAddress>192.168.178.21</IPAddress>
+<MACAddress>02:00:00:00:00:02</MACAddress>
+<Active>0</Active>
+<HostName>phone</HostName>
+<InterfaceType>802.11</InterfaceType>
+<X_AVM-DE_Port>0</X_AVM-DE_Port>
+<X_AVM-DE_Speed>0</X_AVM-DE_Speed>
+<X_AVM-DE_UpdateAvailable>0</X_AVM-DE_UpdateAvailable>
+<X_AVM-DE_Guest>0</X_AVM-DE_Guest>
+<X_AVM-DE_VPN>0</X_AVM-DE_VPN>
+<X_AVM-DE_WANAccess>granted</X_AVM-DE_WANAccess>
+<X_AVM-DE_FriendlyName>phone</X_AVM-DE_FriendlyName>
+</Item>
+</List>
+`
+
+// DOCSISInfo is the data.lua page docInfo of a FRITZ!Box 6591 Cable with
+// DOCSIS 3.0 and 3.1 channels in both directions, see Server.SetWebPage
+const DOCSISInfo = `{"pid":"docInfo","hide":{"mobile":true,"ssoSet":true,"liveTv":true},"timeTillLogout":"1200","data":{"channelUs":{"docsis30":[{"powerLevel":"43.0","type":"64QAM","channel":1,"multiplex":"ATDMA","channelID":2,"frequency":"51.0"},{"powerLevel":"44.3","type":"64QAM","channel":2,"multiplex":"ATDMA","channelID":1,"frequency":"44.6"}],"docsis31":[{"powerLevel":"38.5","type":"OFDMA","channel":3,"channelID":9,"frequency":"29.8 - 64.8","modulation":"1024QAM"}]},"channelDs":{"docsis30":[{"type":"256QAM","corrErrors":12,"mse":"-37.6","powerLevel":"5.1","channel":1,"nonCorrErrors":0,"latency":0.32,"channelID":7,"frequency":"538"},{"type":"256QAM","corrErrors":301,"mse":"-36.4","powerLevel":"4.4","channel":2,"nonCorrErrors":17,"latency":0.32,"channelID":8,"frequency":"546"}],"docsis31":[{"powerLevel":"7.2","type":"4K","channel":3,"channelID":33,"plc":"758","mer":"42","frequency":"751.8 - 861.8","modulation":"4096QAM","corrErrors":5083,"nonCorrErrors":2}]},"readyState":"ready"},"sid":"0000000000000000"}`
+
+// WLANDeviceList is a list of associated devices as downloaded from the path
+// X_AVM-DE_GetWLANDeviceListPath returns, see Server.SetDocument
+const WLANDeviceList = `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<TotalNumber>2</TotalNumber>
+<Item>
+<AssociatedDeviceIndex>0</AssociatedDeviceIndex>
+<AssociatedDeviceMACAddress>02:00:00:00:00:02</AssociatedDeviceMACAddress>
+<AssociatedDeviceIPAddress>192.168.178.21</AssociatedDeviceIPAddress>
+<AssociatedDeviceAuthState>1</AssociatedDeviceAuthState>
+<X_AVM-DE_Speed>866</X_AVM-DE_Speed>
+<X_AVM-DE_SignalStrength>62</X_AVM-DE_SignalStrength>
+<AssociatedDeviceChannel>36</AssociatedDeviceChannel>
+<AssociatedDeviceGuest>0</AssociatedDeviceGuest>
+<X_AVM-DE_SpeedRX>780</X_AVM-DE_SpeedRX>
+<X_AVM-DE_SpeedTX>866</X_AVM-DE_SpeedTX>
+</Item>
+<Item>
+<AssociatedDeviceIndex>1</AssociatedDeviceIndex>
+<AssociatedDeviceMACAddress>02:00:00:00:00:03</AssociatedDeviceMACAddress>
+<AssociatedDeviceIPAddress>192.168.178.22</AssociatedDeviceIPAddress>
+<AssociatedDeviceAuthState>1</AssociatedDeviceAuthState>
+<X_AVM-DE_Speed>144</X_AVM-DE_Speed>
+<X_AVM-DE_SignalStrength>21</X_AVM-DE_SignalStrength>
+<AssociatedDeviceChannel>36</AssociatedDeviceChannel>
+<AssociatedDeviceGuest>0</AssociatedDeviceGuest>
+<X_AVM-DE_SpeedRX>130</X_AVM-DE_SpeedRX>
+<X_AVM-DE_SpeedTX>144</X_AVM-DE_SpeedTX>
+</Item>
+</List>
+`
+
+// MeshList is a mesh topology as downloaded from the path X_AVM-DE_GetMeshListPath
+// returns, with a repeater connected over WLAN and a host connected to the
+// repeater, see Server.SetDocument
+const MeshList = `{
+"schema_version": "5.4",
+"nodes": [
+{"uid": "n-1", "device_name": "fritz.box", "device_model": "FRITZ!Box 7590", "device_manufacturer": "AVM", "device_firmware_version": "154.07.29", "device_mac_address": "02:00:00:00:00:10", "is_meshed": true, "mesh_role": "master", "meshd_version": "3.13",
+ "node_interfaces": [
+  {"uid": "ni-11", "name": "AP:5G:0", "type": "WLAN", "mac_address": "02:00:00:00:00:11", "blocking_state": "NOT_BLOCKED", "ssid": "home", "opmode": "AP", "security": "WPA2_WPA3_MIXED",
+   "node_links": [{"uid": "nl-20", "type": "WLAN", "state": "CONNECTED", "last_connected": 1602582938, "node_1_uid": "n-1", "node_2_uid": "n-2", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-21", "max_data_rate_rx": 1300000, "max_data_rate_tx": 1300000, "cur_data_rate_rx": 866000, "cur_data_rate_tx": 975000, "cur_availability_rx": 99, "cur_availability_tx": 99}]}
+ ]},
+{"uid": "n-2", "device_name": "fritz.repeater", "device_model": "FRITZ!Repeater 3000", "device_manufacturer": "AVM", "device_firmware_version": "181.07.29", "device_mac_address": "02:00:00:00:00:20", "is_meshed": true, "mesh_role": "slave", "meshd_version": "3.13",
+ "node_interfaces": [
+  {"uid": "ni-21", "name": "UPLINK:5G:0", "type": "WLAN", "mac_address": "02:00:00:00:00:21", "blocking_state": "NOT_BLOCKED", "ssid": "home", "opmode": "REPEATER",
+   "node_links": [{"uid": "nl-20", "type": "WLAN", "state": "CONNECTED", "last_connected": 1602582938, "node_1_uid": "n-1", "node_2_uid": "n-2", "node_interface_1_uid": "ni-11", "node_interface_2_uid": "ni-21", "max_data_rate_rx": 1300000, "max_data_rate_tx": 1300000, "cur_data_rate_rx": 866000, "cur_data_rate_tx": 975000, "cur_availability_rx": 99, "cur_availability_tx": 99}]},
+  {"uid": "ni-22", "name": "LAN:1", "type": "LAN", "mac_address": "02:00:00:00:00:22", "blocking_state": "UNKNOWN",
+   "node_links": [{"uid": "nl-30", "type": "LAN", "state": "CONNECTED", "last_connected": 1602582940, "node_1_uid": "n-2", "node_2_uid": "n-3", "node_interface_1_uid": "ni-22", "node_interface_2_uid": "ni-31", "max_data_rate_rx": 1000000, "max_data_rate_tx": 1000000, "cur_data_rate_rx": 0, "cur_data_rate_tx": 0}]}
+ ]},
+{"uid": "n-3", "device_name": "nas", "device_model": "", "device_manufacturer": "", "device_firmware_version": "", "device_mac_address": "02:00:00:00:00:30", "is_meshed": false, "mesh_role": "unknown", "meshd_version": "0.0",
+ "node_interfaces": [
+  {"uid": "ni-31", "name": "", "type": "LAN", "mac_address": "02:00:00:00:00:30", "blocking_state": "UNKNOWN",
+   "node_links": [{"uid": "nl-30", "type": "LAN", "state": "CONNECTED", "last_connected": 1602582940, "node_1_uid": "n-2", "node_2_uid": "n-3", "node_interface_1_uid": "ni-22", "node_interface_2_uid": "ni-31", "max_data_rate_rx": 1000000, "max_data_rate_tx": 1000000, "cur_data_rate_rx": 0, "cur_data_rate_tx": 0}]}
+ ]},
+{"uid": "n-4", "device_name": "old-phone", "device_model": "", "device_mac_address": "02:00:00:00:00:40", "is_meshed": false, "mesh_role": "unknown", "node_interfaces": []}
+]
+}
+`
+
+// VoIPNumberList is the answer of X_VoIP X_AVM-DE_GetNumbers with two
+// internet telephone numbers and a mobile number, see Server.SetResponse
+const VoIPNumberList = `<?xml version="1.0" encoding="utf-8"?>
+<List>
+<Item><Number>0301234567</Number><Type>eVoIP</Type><Index>0</Index><Name>office</Name></Item>
+<Item><Number>0307654321</Number><Type>eVoIP</Type><Index>1</Index><Name>fax</Name></Item>
+<Item><Number>01701234567</Number><Type>eMobile</Type><Index>0</Index><Name></Name></Item>
+</List>
+`
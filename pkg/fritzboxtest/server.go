@@ -0,0 +1,407 @@
+// Package fritzboxtest provides an in-process fake FRITZ!Box for tests.
+//
+// The server serves the description documents of a FRITZ!Box, answers SOAP
+// actions with scripted responses, enforces digest authentication on the
+// TR-064 control URLs and can inject faults like delays, HTTP errors and SOAP faults.
+// It also offers the login and data.lua pages of the web interface and the AHA HTTP interface.
+package fritzboxtest
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	realm        = "F!Box SOAP-Auth"
+	initialNonce = "A5C7E5D0D9E6C2B1"
+
+	// tr64ControlPrefix is the prefix of the control URLs which require authentication
+	tr64ControlPrefix = "/upnp/control/"
+)
+
+// Handler answers a SOAP action. args and the returned outputs are indexed
+// by the argument name, e.g. NewIndex. A returned *Fault is sent as SOAP fault.
+type Handler func(args map[string]string) (map[string]string, error)
+
+// Fault describes an error the server answers an action with
+type Fault struct {
+	Delay                time.Duration // Delay before answering
+	StatusCode           int           // Answer with this HTTP status and no body, if set
+	UPnPErrorCode        int           // Answer with a SOAP fault with this code, if set
+	UPnPErrorDescription string
+}
+
+// Error implements error, so handlers can return a *Fault
+func (f *Fault) Error() string {
+	return fmt.Sprintf("UPnP error %d: %s", f.UPnPErrorCode, f.UPnPErrorDescription)
+}
+
+// Options configure a Server
+type Options struct {
+	Username string
+	Password string
+	// Documents served by the server indexed by path, Documents() if nil
+	Documents map[string]string
+}
+
+// Server is a fake FRITZ!Box
+type Server struct {
+	*httptest.Server
+
+	username string
+	password string
+
+	mu        sync.Mutex // protects the fields below
+	nonce     string
+	nonces    int
+	documents map[string]string
+	handlers  map[string]Handler
+	controls  map[string]Handler
+	faults    map[string]Fault
+	calls     map[string]int
+	fetches   map[string]int
+
+	webPages     map[string]string
+	ahaResponses map[string]string
+	sessions     map[string]bool
+	nextSID      uint64
+}
+
+// NewServer starts a new fake FRITZ!Box. It has to be closed by the caller.
+func NewServer(opts Options) *Server {
+	docs := opts.Documents
+	if docs == nil {
+		docs = Documents()
+	}
+
+	s := &Server{
+		username:     opts.Username,
+		password:     opts.Password,
+		nonce:        initialNonce,
+		documents:    docs,
+		handlers:     make(map[string]Handler),
+		controls:     make(map[string]Handler),
+		faults:       make(map[string]Fault),
+		calls:        make(map[string]int),
+		fetches:      make(map[string]int),
+		webPages:     make(map[string]string),
+		ahaResponses: make(map[string]string),
+		sessions:     make(map[string]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Host returns the host the server listens on, usable as device for LoadServices
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *Server) Port() uint16 {
+	_, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	p, _ := strconv.ParseUint(port, 10, 16)
+	return uint16(p)
+}
+
+// SetDocument serves content at the given path, e.g. for a host list
+func (s *Server) SetDocument(path, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[path] = content
+}
+
+// Handle answers the action of the service type with the handler
+func (s *Server) Handle(serviceType, action string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[actionKey(serviceType, action)] = h
+}
+
+// HandleControl answers the action of the service at the control URL with
+// the handler, e.g. to tell the instances of WLANConfiguration apart. It takes
+// precedence over the handler of the service type.
+func (s *Server) HandleControl(controlURL, action string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controls[actionKey(controlURL, action)] = h
+}
+
+// SetResponse answers the action of the service type with fixed output arguments
+func (s *Server) SetResponse(serviceType, action string, outputs map[string]string) {
+	s.Handle(serviceType, action, func(map[string]string) (map[string]string, error) {
+		return outputs, nil
+	})
+}
+
+// InjectFault makes the action of the service type fail. An empty action
+// injects the fault into all actions of the service type.
+func (s *Server) InjectFault(serviceType, action string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[actionKey(serviceType, action)] = f
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string]Fault)
+}
+
+// ExpireNonce makes the server answer with a new digest challenge, as a
+// FRITZ!Box does when a nonce becomes stale
+func (s *Server) ExpireNonce() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonces++
+	s.nonce = fmt.Sprintf("%s%04X", initialNonce[:12], s.nonces)
+}
+
+// Calls returns how often the action of the service type was called successfully authenticated
+func (s *Server) Calls(serviceType, action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[actionKey(serviceType, action)]
+}
+
+// Fetches returns how often the document at the path was downloaded
+func (s *Server) Fetches(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches[path]
+}
+
+func actionKey(serviceType, action string) string {
+	return serviceType + "#" + action
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/login_sid.lua":
+		s.serveLogin(w, r)
+		return
+	case "/data.lua":
+		s.serveData(w, r)
+		return
+	case "/webservices/homeautoswitch.lua":
+		s.serveAHA(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		s.serveSOAP(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	doc, ok := s.documents[r.URL.Path]
+	if ok {
+		s.fetches[r.URL.Path]++
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	io.WriteString(w, doc)
+}
+
+func (s *Server) serveSOAP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, tr64ControlPrefix) && !s.authorized(r) {
+		s.mu.Lock()
+		nonce := s.nonce
+		s.mu.Unlock()
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", nonce="%s", algorithm=MD5, qop="auth"`, realm, nonce))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// SoapAction: urn:dslforum-org:service:DeviceInfo:1#GetInfo
+	soapAction := strings.Trim(r.Header.Get("SoapAction"), `"`)
+	sep := strings.LastIndex(soapAction, "#")
+	if sep < 0 {
+		http.Error(w, "missing SoapAction", http.StatusBadRequest)
+		return
+	}
+	serviceType, action := soapAction[:sep], soapAction[sep+1:]
+
+	args, err := parseArguments(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	fault, hasFault := s.faults[actionKey(serviceType, action)]
+	if !hasFault {
+		fault, hasFault = s.faults[actionKey(serviceType, "")]
+	}
+	handler, hasHandler := s.controls[actionKey(r.URL.Path, action)]
+	if !hasHandler {
+		handler, hasHandler = s.handlers[actionKey(serviceType, action)]
+	}
+	s.calls[actionKey(serviceType, action)]++
+	s.mu.Unlock()
+
+	if hasFault {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 {
+			w.WriteHeader(fault.StatusCode)
+			return
+		}
+		if fault.UPnPErrorCode != 0 {
+			writeFault(w, &fault)
+			return
+		}
+	}
+
+	if !hasHandler {
+		writeFault(w, &Fault{UPnPErrorCode: 401, UPnPErrorDescription: "Invalid Action"})
+		return
+	}
+
+	outputs, err := handler(args)
+	if err != nil {
+		f, ok := err.(*Fault)
+		if !ok {
+			f = &Fault{UPnPErrorCode: 501, UPnPErrorDescription: err.Error()}
+		}
+		writeFault(w, f)
+		return
+	}
+	writeResponse(w, serviceType, action, outputs)
+}
+
+// authorized checks the digest Authorization header of the request
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Digest ") {
+		return false
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(header[len("Digest "):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	s.mu.Lock()
+	nonce := s.nonce
+	s.mu.Unlock()
+	if params["username"] != s.username || params["nonce"] != nonce {
+		return false
+	}
+
+	ha1 := md5Hex(fmt.Sprintf("%s:%s:%s", s.username, realm, s.password))
+	ha2 := md5Hex(fmt.Sprintf("%s:%s", r.Method, params["uri"]))
+	var expected string
+	if params["qop"] != "" {
+		expected = md5Hex(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2))
+	} else {
+		expected = md5Hex(fmt.Sprintf("%s:%s:%s", ha1, nonce, ha2))
+	}
+	return params["response"] == expected
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseArguments returns the child elements of the action element in the SOAP body
+func parseArguments(r io.Reader) (map[string]string, error) {
+	var env struct {
+		Body struct {
+			Action struct {
+				Arguments []struct {
+					XMLName xml.Name
+					Value   string `xml:",chardata"`
+				} `xml:",any"`
+			} `xml:",any"`
+		} `xml:"Body"`
+	}
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("invalid SOAP request: %w", err)
+	}
+
+	args := make(map[string]string)
+	for _, arg := range env.Body.Action.Arguments {
+		args[arg.XMLName.Local] = arg.Value
+	}
+	return args, nil
+}
+
+func writeResponse(w http.ResponseWriter, serviceType, action string, outputs map[string]string) {
+	var body strings.Builder
+	for name, value := range outputs {
+		body.WriteString("<" + name + ">")
+		xml.EscapeText(&body, []byte(value))
+		body.WriteString("</" + name + ">")
+	}
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	fmt.Fprintf(w, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+<s:Body>
+<u:%sResponse xmlns:u="%s">%s</u:%sResponse>
+</s:Body>
+</s:Envelope>`, action, serviceType, body.String(), action)
+}
+
+func writeFault(w http.ResponseWriter, f *Fault) {
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusInternalServerError)
+
+	var desc strings.Builder
+	xml.EscapeText(&desc, []byte(f.UPnPErrorDescription))
+	fmt.Fprintf(w, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+<s:Body>
+<s:Fault>
+<faultcode>s:Client</faultcode>
+<faultstring>UPnPError</faultstring>
+<detail>
+<UPnPError xmlns="urn:dslforum-org:control-1-0">
+<errorCode>%d</errorCode>
+<errorDescription>%s</errorDescription>
+</UPnPError>
+</detail>
+</s:Fault>
+</s:Body>
+</s:Envelope>`, f.UPnPErrorCode, desc.String())
+}
@@ -0,0 +1,147 @@
+package fritzboxtest
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// webChallenge is the PBKDF2 challenge of login_sid.lua, with few iterations to keep tests fast
+	webChallenge = "2$10$5A1711$10$5A1722"
+	invalidSID   = "0000000000000000"
+)
+
+// SetWebPage answers POST requests to /data.lua for the page (e.g. docInfo)
+// with content, if the request has a valid session ID
+func (s *Server) SetWebPage(page, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webPages[page] = content
+}
+
+// SetAHAResponse answers the AHA HTTP interface command (e.g. getdevicelistinfos)
+// with content, if the request has a valid session ID
+func (s *Server) SetAHAResponse(switchcmd, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ahaResponses[switchcmd] = content
+}
+
+// ExpireSessions invalidates all session IDs of the web interface
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// Sessions returns the number of web interface sessions which are logged in
+func (s *Server) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Server) serveLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sid := invalidSID
+	switch {
+	case r.Form.Get("logout") != "":
+		delete(s.sessions, r.Form.Get("sid"))
+	case r.Form.Get("response") != "":
+		if r.Form.Get("username") == s.username && r.Form.Get("response") == webResponse(s.password) {
+			s.nextSID++
+			sid = fmt.Sprintf("%016x", s.nextSID)
+			s.sessions[sid] = true
+		}
+	case s.sessions[r.Form.Get("sid")]:
+		sid = r.Form.Get("sid")
+	}
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<SessionInfo><SID>%s</SID><Challenge>%s</Challenge><BlockTime>0</BlockTime><Rights></Rights><Users><User last="1">%s</User></Users></SessionInfo>`,
+		sid, webChallenge, s.username)
+}
+
+func (s *Server) serveData(w http.ResponseWriter, r *http.Request) {
+	s.serveSessionPage(w, r, s.webPages, "page", "application/json")
+}
+
+func (s *Server) serveAHA(w http.ResponseWriter, r *http.Request) {
+	s.serveSessionPage(w, r, s.ahaResponses, "switchcmd", `text/xml; charset="utf-8"`)
+}
+
+// serveSessionPage answers with the content for the form value key, if the request has a valid session ID
+func (s *Server) serveSessionPage(w http.ResponseWriter, r *http.Request, pages map[string]string, key, contentType string) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	valid := s.sessions[r.Form.Get("sid")]
+	content, ok := pages[r.Form.Get(key)]
+	s.mu.Unlock()
+
+	if !valid {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	io.WriteString(w, content)
+}
+
+// webResponse is the expected response to webChallenge
+func webResponse(password string) string {
+	hash1 := pbkdf2SHA256([]byte(password), []byte{0x5a, 0x17, 0x11}, 10)
+	hash2 := pbkdf2SHA256(hash1, []byte{0x5a, 0x17, 0x22}, 10)
+	return "5A1722$" + hex.EncodeToString(hash2)
+}
+
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
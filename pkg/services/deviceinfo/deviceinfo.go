@@ -0,0 +1,140 @@
+// Code generated by scpdgen from deviceinfoSCPD.xml. DO NOT EDIT.
+
+// Package deviceinfo is a typed client for the DeviceInfo service.
+package deviceinfo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of DeviceInfo
+const ServiceType = "urn:dslforum-org:service:DeviceInfo:1"
+
+// Client calls the actions of a DeviceInfo service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first DeviceInfo service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetInfoResponse are the output arguments of GetInfo
+type GetInfoResponse struct {
+	ManufacturerName string // NewManufacturerName (string)
+	ManufacturerOUI  string // NewManufacturerOUI (string)
+	ModelName        string // NewModelName (string)
+	Description      string // NewDescription (string)
+	ProductClass     string // NewProductClass (string)
+	SerialNumber     string // NewSerialNumber (string)
+	SoftwareVersion  string // NewSoftwareVersion (string)
+	HardwareVersion  string // NewHardwareVersion (string)
+	SpecVersion      string // NewSpecVersion (string)
+	ProvisioningCode string // NewProvisioningCode (string)
+	UpTime           uint64 // NewUpTime (ui4)
+	DeviceLog        string // NewDeviceLog (string)
+}
+
+// GetInfo calls GetInfo
+func (c *Client) GetInfo(ctx context.Context) (GetInfoResponse, error) {
+	var resp GetInfoResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetInfo", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewManufacturerName", &resp.ManufacturerName)
+	dec.Decode("NewManufacturerOUI", &resp.ManufacturerOUI)
+	dec.Decode("NewModelName", &resp.ModelName)
+	dec.Decode("NewDescription", &resp.Description)
+	dec.Decode("NewProductClass", &resp.ProductClass)
+	dec.Decode("NewSerialNumber", &resp.SerialNumber)
+	dec.Decode("NewSoftwareVersion", &resp.SoftwareVersion)
+	dec.Decode("NewHardwareVersion", &resp.HardwareVersion)
+	dec.Decode("NewSpecVersion", &resp.SpecVersion)
+	dec.Decode("NewProvisioningCode", &resp.ProvisioningCode)
+	dec.Decode("NewUpTime", &resp.UpTime)
+	dec.Decode("NewDeviceLog", &resp.DeviceLog)
+	return resp, dec.Err()
+}
+
+// SetProvisioningCodeResponse are the output arguments of SetProvisioningCode
+type SetProvisioningCodeResponse struct {
+}
+
+// SetProvisioningCode calls SetProvisioningCode
+func (c *Client) SetProvisioningCode(ctx context.Context, provisioningCode string) (SetProvisioningCodeResponse, error) {
+	var resp SetProvisioningCodeResponse
+	args := map[string]interface{}{
+		"NewProvisioningCode": provisioningCode,
+	}
+
+	_, _, err := c.call(ctx, "SetProvisioningCode", args)
+	if err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// GetDeviceLogResponse are the output arguments of GetDeviceLog
+type GetDeviceLogResponse struct {
+	DeviceLog string // NewDeviceLog (string)
+}
+
+// GetDeviceLog calls GetDeviceLog
+func (c *Client) GetDeviceLog(ctx context.Context) (GetDeviceLogResponse, error) {
+	var resp GetDeviceLogResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetDeviceLog", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewDeviceLog", &resp.DeviceLog)
+	return resp, dec.Err()
+}
+
+// GetSecurityPortResponse are the output arguments of GetSecurityPort
+type GetSecurityPortResponse struct {
+	SecurityPort uint16 // NewSecurityPort (ui2)
+}
+
+// GetSecurityPort calls GetSecurityPort
+func (c *Client) GetSecurityPort(ctx context.Context) (GetSecurityPortResponse, error) {
+	var resp GetSecurityPortResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetSecurityPort", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewSecurityPort", &resp.SecurityPort)
+	return resp, dec.Err()
+}
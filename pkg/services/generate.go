@@ -0,0 +1,28 @@
+// Package services contains typed clients for the services of the FRITZ!Box.
+//
+// The clients are generated by cmd/scpdgen from the SCPD descriptions saved
+// in the scpd directory. To add a service, save its SCPD (e.g. from
+// http://fritz.box:49000/<SCPDURL>) and add a go:generate line below.
+package services
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate go run ../../cmd/scpdgen -scpd scpd/igdicfgSCPD.xml -type urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1 -pkg wancommoninterfaceconfig
+//go:generate go run ../../cmd/scpdgen -scpd scpd/deviceinfoSCPD.xml -type urn:dslforum-org:service:DeviceInfo:1 -pkg deviceinfo
+//go:generate go run ../../cmd/scpdgen -scpd scpd/hostsSCPD.xml -type urn:dslforum-org:service:Hosts:1 -pkg hosts
+//go:generate go run ../../cmd/scpdgen -scpd scpd/x_homeautoSCPD.xml -type urn:dslforum-org:service:X_AVM-DE_Homeauto:1 -pkg homeauto
+//go:generate go run ../../cmd/scpdgen -scpd scpd/wlanconfigSCPD.xml -type urn:dslforum-org:service:WLANConfiguration:1 -pkg wlanconfiguration
+//go:generate go run ../../cmd/scpdgen -scpd scpd/ethifconfigSCPD.xml -type urn:dslforum-org:service:LANEthernetInterfaceConfig:1 -pkg lanethernetinterfaceconfig
+//go:generate go run ../../cmd/scpdgen -scpd scpd/x_voipSCPD.xml -type urn:dslforum-org:service:X_VoIP:1 -pkg voip
@@ -0,0 +1,264 @@
+// Code generated by scpdgen from x_homeautoSCPD.xml. DO NOT EDIT.
+
+// Package homeauto is a typed client for the XAVMDEHomeauto service.
+package homeauto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of XAVMDEHomeauto
+const ServiceType = "urn:dslforum-org:service:X_AVM-DE_Homeauto:1"
+
+// Client calls the actions of a XAVMDEHomeauto service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first XAVMDEHomeauto service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetInfoResponse are the output arguments of GetInfo
+type GetInfoResponse struct {
+	AllowedCharsAIN    string // NewAllowedCharsAIN (string)
+	MaxCharsAIN        uint16 // NewMaxCharsAIN (ui2)
+	MinCharsAIN        uint16 // NewMinCharsAIN (ui2)
+	MaxCharsDeviceName uint16 // NewMaxCharsDeviceName (ui2)
+	MinCharsDeviceName uint16 // NewMinCharsDeviceName (ui2)
+}
+
+// GetInfo calls GetInfo
+func (c *Client) GetInfo(ctx context.Context) (GetInfoResponse, error) {
+	var resp GetInfoResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetInfo", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewAllowedCharsAIN", &resp.AllowedCharsAIN)
+	dec.Decode("NewMaxCharsAIN", &resp.MaxCharsAIN)
+	dec.Decode("NewMinCharsAIN", &resp.MinCharsAIN)
+	dec.Decode("NewMaxCharsDeviceName", &resp.MaxCharsDeviceName)
+	dec.Decode("NewMinCharsDeviceName", &resp.MinCharsDeviceName)
+	return resp, dec.Err()
+}
+
+// GetGenericDeviceInfosResponse are the output arguments of GetGenericDeviceInfos
+type GetGenericDeviceInfosResponse struct {
+	AIN                    string // NewAIN (string)
+	DeviceId               uint16 // NewDeviceId (ui2)
+	FunctionBitMask        uint16 // NewFunctionBitMask (ui2)
+	FirmwareVersion        string // NewFirmwareVersion (string)
+	Manufacturer           string // NewManufacturer (string)
+	ProductName            string // NewProductName (string)
+	DeviceName             string // NewDeviceName (string)
+	Present                string // NewPresent (string)
+	MultimeterIsEnabled    string // NewMultimeterIsEnabled (string)
+	MultimeterIsValid      string // NewMultimeterIsValid (string)
+	MultimeterPower        uint64 // NewMultimeterPower (ui4)
+	MultimeterEnergy       uint64 // NewMultimeterEnergy (ui4)
+	TemperatureIsEnabled   string // NewTemperatureIsEnabled (string)
+	TemperatureIsValid     string // NewTemperatureIsValid (string)
+	TemperatureCelsius     int64  // NewTemperatureCelsius (i4)
+	TemperatureOffset      int64  // NewTemperatureOffset (i4)
+	SwitchIsEnabled        string // NewSwitchIsEnabled (string)
+	SwitchIsValid          string // NewSwitchIsValid (string)
+	SwitchState            string // NewSwitchState (string)
+	SwitchMode             string // NewSwitchMode (string)
+	SwitchLock             bool   // NewSwitchLock (boolean)
+	HkrIsEnabled           string // NewHkrIsEnabled (string)
+	HkrIsValid             string // NewHkrIsValid (string)
+	HkrIsTemperature       uint64 // NewHkrIsTemperature (ui4)
+	HkrSetVentilStatus     string // NewHkrSetVentilStatus (string)
+	HkrSetTemperature      uint64 // NewHkrSetTemperature (ui4)
+	HkrReduceVentilStatus  string // NewHkrReduceVentilStatus (string)
+	HkrReduceTemperature   uint64 // NewHkrReduceTemperature (ui4)
+	HkrComfortVentilStatus string // NewHkrComfortVentilStatus (string)
+	HkrComfortTemperature  uint64 // NewHkrComfortTemperature (ui4)
+}
+
+// GetGenericDeviceInfos calls GetGenericDeviceInfos
+func (c *Client) GetGenericDeviceInfos(ctx context.Context, index uint16) (GetGenericDeviceInfosResponse, error) {
+	var resp GetGenericDeviceInfosResponse
+	args := map[string]interface{}{
+		"NewIndex": index,
+	}
+
+	action, res, err := c.call(ctx, "GetGenericDeviceInfos", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewAIN", &resp.AIN)
+	dec.Decode("NewDeviceId", &resp.DeviceId)
+	dec.Decode("NewFunctionBitMask", &resp.FunctionBitMask)
+	dec.Decode("NewFirmwareVersion", &resp.FirmwareVersion)
+	dec.Decode("NewManufacturer", &resp.Manufacturer)
+	dec.Decode("NewProductName", &resp.ProductName)
+	dec.Decode("NewDeviceName", &resp.DeviceName)
+	dec.Decode("NewPresent", &resp.Present)
+	dec.Decode("NewMultimeterIsEnabled", &resp.MultimeterIsEnabled)
+	dec.Decode("NewMultimeterIsValid", &resp.MultimeterIsValid)
+	dec.Decode("NewMultimeterPower", &resp.MultimeterPower)
+	dec.Decode("NewMultimeterEnergy", &resp.MultimeterEnergy)
+	dec.Decode("NewTemperatureIsEnabled", &resp.TemperatureIsEnabled)
+	dec.Decode("NewTemperatureIsValid", &resp.TemperatureIsValid)
+	dec.Decode("NewTemperatureCelsius", &resp.TemperatureCelsius)
+	dec.Decode("NewTemperatureOffset", &resp.TemperatureOffset)
+	dec.Decode("NewSwitchIsEnabled", &resp.SwitchIsEnabled)
+	dec.Decode("NewSwitchIsValid", &resp.SwitchIsValid)
+	dec.Decode("NewSwitchState", &resp.SwitchState)
+	dec.Decode("NewSwitchMode", &resp.SwitchMode)
+	dec.Decode("NewSwitchLock", &resp.SwitchLock)
+	dec.Decode("NewHkrIsEnabled", &resp.HkrIsEnabled)
+	dec.Decode("NewHkrIsValid", &resp.HkrIsValid)
+	dec.Decode("NewHkrIsTemperature", &resp.HkrIsTemperature)
+	dec.Decode("NewHkrSetVentilStatus", &resp.HkrSetVentilStatus)
+	dec.Decode("NewHkrSetTemperature", &resp.HkrSetTemperature)
+	dec.Decode("NewHkrReduceVentilStatus", &resp.HkrReduceVentilStatus)
+	dec.Decode("NewHkrReduceTemperature", &resp.HkrReduceTemperature)
+	dec.Decode("NewHkrComfortVentilStatus", &resp.HkrComfortVentilStatus)
+	dec.Decode("NewHkrComfortTemperature", &resp.HkrComfortTemperature)
+	return resp, dec.Err()
+}
+
+// GetSpecificDeviceInfosResponse are the output arguments of GetSpecificDeviceInfos
+type GetSpecificDeviceInfosResponse struct {
+	DeviceId               uint16 // NewDeviceId (ui2)
+	FunctionBitMask        uint16 // NewFunctionBitMask (ui2)
+	FirmwareVersion        string // NewFirmwareVersion (string)
+	Manufacturer           string // NewManufacturer (string)
+	ProductName            string // NewProductName (string)
+	DeviceName             string // NewDeviceName (string)
+	Present                string // NewPresent (string)
+	MultimeterIsEnabled    string // NewMultimeterIsEnabled (string)
+	MultimeterIsValid      string // NewMultimeterIsValid (string)
+	MultimeterPower        uint64 // NewMultimeterPower (ui4)
+	MultimeterEnergy       uint64 // NewMultimeterEnergy (ui4)
+	TemperatureIsEnabled   string // NewTemperatureIsEnabled (string)
+	TemperatureIsValid     string // NewTemperatureIsValid (string)
+	TemperatureCelsius     int64  // NewTemperatureCelsius (i4)
+	TemperatureOffset      int64  // NewTemperatureOffset (i4)
+	SwitchIsEnabled        string // NewSwitchIsEnabled (string)
+	SwitchIsValid          string // NewSwitchIsValid (string)
+	SwitchState            string // NewSwitchState (string)
+	SwitchMode             string // NewSwitchMode (string)
+	SwitchLock             bool   // NewSwitchLock (boolean)
+	HkrIsEnabled           string // NewHkrIsEnabled (string)
+	HkrIsValid             string // NewHkrIsValid (string)
+	HkrIsTemperature       uint64 // NewHkrIsTemperature (ui4)
+	HkrSetVentilStatus     string // NewHkrSetVentilStatus (string)
+	HkrSetTemperature      uint64 // NewHkrSetTemperature (ui4)
+	HkrReduceVentilStatus  string // NewHkrReduceVentilStatus (string)
+	HkrReduceTemperature   uint64 // NewHkrReduceTemperature (ui4)
+	HkrComfortVentilStatus string // NewHkrComfortVentilStatus (string)
+	HkrComfortTemperature  uint64 // NewHkrComfortTemperature (ui4)
+}
+
+// GetSpecificDeviceInfos calls GetSpecificDeviceInfos
+func (c *Client) GetSpecificDeviceInfos(ctx context.Context, ain string) (GetSpecificDeviceInfosResponse, error) {
+	var resp GetSpecificDeviceInfosResponse
+	args := map[string]interface{}{
+		"NewAIN": ain,
+	}
+
+	action, res, err := c.call(ctx, "GetSpecificDeviceInfos", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewDeviceId", &resp.DeviceId)
+	dec.Decode("NewFunctionBitMask", &resp.FunctionBitMask)
+	dec.Decode("NewFirmwareVersion", &resp.FirmwareVersion)
+	dec.Decode("NewManufacturer", &resp.Manufacturer)
+	dec.Decode("NewProductName", &resp.ProductName)
+	dec.Decode("NewDeviceName", &resp.DeviceName)
+	dec.Decode("NewPresent", &resp.Present)
+	dec.Decode("NewMultimeterIsEnabled", &resp.MultimeterIsEnabled)
+	dec.Decode("NewMultimeterIsValid", &resp.MultimeterIsValid)
+	dec.Decode("NewMultimeterPower", &resp.MultimeterPower)
+	dec.Decode("NewMultimeterEnergy", &resp.MultimeterEnergy)
+	dec.Decode("NewTemperatureIsEnabled", &resp.TemperatureIsEnabled)
+	dec.Decode("NewTemperatureIsValid", &resp.TemperatureIsValid)
+	dec.Decode("NewTemperatureCelsius", &resp.TemperatureCelsius)
+	dec.Decode("NewTemperatureOffset", &resp.TemperatureOffset)
+	dec.Decode("NewSwitchIsEnabled", &resp.SwitchIsEnabled)
+	dec.Decode("NewSwitchIsValid", &resp.SwitchIsValid)
+	dec.Decode("NewSwitchState", &resp.SwitchState)
+	dec.Decode("NewSwitchMode", &resp.SwitchMode)
+	dec.Decode("NewSwitchLock", &resp.SwitchLock)
+	dec.Decode("NewHkrIsEnabled", &resp.HkrIsEnabled)
+	dec.Decode("NewHkrIsValid", &resp.HkrIsValid)
+	dec.Decode("NewHkrIsTemperature", &resp.HkrIsTemperature)
+	dec.Decode("NewHkrSetVentilStatus", &resp.HkrSetVentilStatus)
+	dec.Decode("NewHkrSetTemperature", &resp.HkrSetTemperature)
+	dec.Decode("NewHkrReduceVentilStatus", &resp.HkrReduceVentilStatus)
+	dec.Decode("NewHkrReduceTemperature", &resp.HkrReduceTemperature)
+	dec.Decode("NewHkrComfortVentilStatus", &resp.HkrComfortVentilStatus)
+	dec.Decode("NewHkrComfortTemperature", &resp.HkrComfortTemperature)
+	return resp, dec.Err()
+}
+
+// SetDeviceNameResponse are the output arguments of SetDeviceName
+type SetDeviceNameResponse struct {
+}
+
+// SetDeviceName calls SetDeviceName
+func (c *Client) SetDeviceName(ctx context.Context, ain string, deviceName string) (SetDeviceNameResponse, error) {
+	var resp SetDeviceNameResponse
+	args := map[string]interface{}{
+		"NewAIN":        ain,
+		"NewDeviceName": deviceName,
+	}
+
+	_, _, err := c.call(ctx, "SetDeviceName", args)
+	if err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// SetSwitchResponse are the output arguments of SetSwitch
+type SetSwitchResponse struct {
+}
+
+// SetSwitch calls SetSwitch
+func (c *Client) SetSwitch(ctx context.Context, ain string, switchState string) (SetSwitchResponse, error) {
+	var resp SetSwitchResponse
+	args := map[string]interface{}{
+		"NewAIN":         ain,
+		"NewSwitchState": switchState,
+	}
+
+	_, _, err := c.call(ctx, "SetSwitch", args)
+	if err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package hosts
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// Host is an entry of the host list downloaded from X_AVM-DE_GetHostListPath
+type Host struct {
+	Index         int    `xml:"Index"`
+	IPAddress     string `xml:"IPAddress"`
+	MACAddress    string `xml:"MACAddress"`
+	Active        bool   `xml:"Active"`
+	HostName      string `xml:"HostName"`
+	InterfaceType string `xml:"InterfaceType"` // Ethernet, 802.11 or empty
+	Port          int    `xml:"X_AVM-DE_Port"`
+	Speed         int    `xml:"X_AVM-DE_Speed"` // Link speed in Mbit/s, 0 if unknown
+	Guest         bool   `xml:"X_AVM-DE_Guest"`
+	VPN           bool   `xml:"X_AVM-DE_VPN"`
+	WANAccess     string `xml:"X_AVM-DE_WANAccess"`
+	FriendlyName  string `xml:"X_AVM-DE_FriendlyName"`
+}
+
+// HostList downloads the list of all hosts with a single request,
+// instead of calling GetGenericHostEntry for every host
+func (c *Client) HostList(ctx context.Context) ([]Host, error) {
+	path, err := c.XAVMDEGetHostListPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Service.Fetch(ctx, path.XAVMDEHostListPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch host list: %w", err)
+	}
+
+	var list struct {
+		Items []Host `xml:"Item"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("could not decode host list: %w", err)
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,166 @@
+// Code generated by scpdgen from hostsSCPD.xml. DO NOT EDIT.
+
+// Package hosts is a typed client for the Hosts service.
+package hosts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of Hosts
+const ServiceType = "urn:dslforum-org:service:Hosts:1"
+
+// Client calls the actions of a Hosts service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first Hosts service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetHostNumberOfEntriesResponse are the output arguments of GetHostNumberOfEntries
+type GetHostNumberOfEntriesResponse struct {
+	HostNumberOfEntries uint16 // NewHostNumberOfEntries (ui2)
+}
+
+// GetHostNumberOfEntries calls GetHostNumberOfEntries
+func (c *Client) GetHostNumberOfEntries(ctx context.Context) (GetHostNumberOfEntriesResponse, error) {
+	var resp GetHostNumberOfEntriesResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetHostNumberOfEntries", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewHostNumberOfEntries", &resp.HostNumberOfEntries)
+	return resp, dec.Err()
+}
+
+// GetSpecificHostEntryResponse are the output arguments of GetSpecificHostEntry
+type GetSpecificHostEntryResponse struct {
+	IPAddress          string // NewIPAddress (string)
+	AddressSource      string // NewAddressSource (string)
+	LeaseTimeRemaining int64  // NewLeaseTimeRemaining (i4)
+	InterfaceType      string // NewInterfaceType (string)
+	Active             bool   // NewActive (boolean)
+	HostName           string // NewHostName (string)
+}
+
+// GetSpecificHostEntry calls GetSpecificHostEntry
+func (c *Client) GetSpecificHostEntry(ctx context.Context, macAddress string) (GetSpecificHostEntryResponse, error) {
+	var resp GetSpecificHostEntryResponse
+	args := map[string]interface{}{
+		"NewMACAddress": macAddress,
+	}
+
+	action, res, err := c.call(ctx, "GetSpecificHostEntry", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewIPAddress", &resp.IPAddress)
+	dec.Decode("NewAddressSource", &resp.AddressSource)
+	dec.Decode("NewLeaseTimeRemaining", &resp.LeaseTimeRemaining)
+	dec.Decode("NewInterfaceType", &resp.InterfaceType)
+	dec.Decode("NewActive", &resp.Active)
+	dec.Decode("NewHostName", &resp.HostName)
+	return resp, dec.Err()
+}
+
+// GetGenericHostEntryResponse are the output arguments of GetGenericHostEntry
+type GetGenericHostEntryResponse struct {
+	IPAddress          string // NewIPAddress (string)
+	AddressSource      string // NewAddressSource (string)
+	LeaseTimeRemaining int64  // NewLeaseTimeRemaining (i4)
+	MACAddress         string // NewMACAddress (string)
+	InterfaceType      string // NewInterfaceType (string)
+	Active             bool   // NewActive (boolean)
+	HostName           string // NewHostName (string)
+}
+
+// GetGenericHostEntry calls GetGenericHostEntry
+func (c *Client) GetGenericHostEntry(ctx context.Context, index uint16) (GetGenericHostEntryResponse, error) {
+	var resp GetGenericHostEntryResponse
+	args := map[string]interface{}{
+		"NewIndex": index,
+	}
+
+	action, res, err := c.call(ctx, "GetGenericHostEntry", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewIPAddress", &resp.IPAddress)
+	dec.Decode("NewAddressSource", &resp.AddressSource)
+	dec.Decode("NewLeaseTimeRemaining", &resp.LeaseTimeRemaining)
+	dec.Decode("NewMACAddress", &resp.MACAddress)
+	dec.Decode("NewInterfaceType", &resp.InterfaceType)
+	dec.Decode("NewActive", &resp.Active)
+	dec.Decode("NewHostName", &resp.HostName)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetHostListPathResponse are the output arguments of X_AVM-DE_GetHostListPath
+type XAVMDEGetHostListPathResponse struct {
+	XAVMDEHostListPath string // NewX_AVM-DE_HostListPath (string)
+}
+
+// XAVMDEGetHostListPath calls X_AVM-DE_GetHostListPath
+func (c *Client) XAVMDEGetHostListPath(ctx context.Context) (XAVMDEGetHostListPathResponse, error) {
+	var resp XAVMDEGetHostListPathResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetHostListPath", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewX_AVM-DE_HostListPath", &resp.XAVMDEHostListPath)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetMeshListPathResponse are the output arguments of X_AVM-DE_GetMeshListPath
+type XAVMDEGetMeshListPathResponse struct {
+	XAVMDEMeshListPath string // NewX_AVM-DE_MeshListPath (string)
+}
+
+// XAVMDEGetMeshListPath calls X_AVM-DE_GetMeshListPath
+func (c *Client) XAVMDEGetMeshListPath(ctx context.Context) (XAVMDEGetMeshListPathResponse, error) {
+	var resp XAVMDEGetMeshListPathResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetMeshListPath", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewX_AVM-DE_MeshListPath", &resp.XAVMDEMeshListPath)
+	return resp, dec.Err()
+}
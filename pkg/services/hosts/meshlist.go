@@ -0,0 +1,93 @@
+package hosts
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Mesh is the mesh topology downloaded from X_AVM-DE_GetMeshListPath.
+// Every host is a node, the FRITZ!Box and the repeaters have the mesh roles.
+type Mesh struct {
+	SchemaVersion string     `json:"schema_version"`
+	Nodes         []MeshNode `json:"nodes"`
+}
+
+// MeshNode is a device in the mesh
+type MeshNode struct {
+	UID             string          `json:"uid"`
+	DeviceName      string          `json:"device_name"`
+	DeviceModel     string          `json:"device_model"`
+	Manufacturer    string          `json:"device_manufacturer"`
+	FirmwareVersion string          `json:"device_firmware_version"`
+	MACAddress      string          `json:"device_mac_address"`
+	IsMeshed        bool            `json:"is_meshed"`
+	MeshRole        string          `json:"mesh_role"` // master, slave or unknown
+	Interfaces      []MeshInterface `json:"node_interfaces"`
+}
+
+// MeshInterface is a network interface of a mesh node
+type MeshInterface struct {
+	UID        string     `json:"uid"`
+	Name       string     `json:"name"` // e.g. LAN:1 or AP:5G:0
+	Type       string     `json:"type"` // LAN, WLAN or PLC
+	MACAddress string     `json:"mac_address"`
+	SSID       string     `json:"ssid"`
+	OpMode     string     `json:"opmode"` // AP, REPEATER, STATION, ...
+	Links      []MeshLink `json:"node_links"`
+}
+
+// MeshLink connects the interfaces of two mesh nodes. Every link is listed
+// at the interfaces of both nodes.
+type MeshLink struct {
+	UID          string `json:"uid"`
+	Type         string `json:"type"`
+	State        string `json:"state"` // CONNECTED or DISCONNECTED
+	Node1UID     string `json:"node_1_uid"`
+	Node2UID     string `json:"node_2_uid"`
+	Interface1ID string `json:"node_interface_1_uid"`
+	Interface2ID string `json:"node_interface_2_uid"`
+
+	MaxDataRateRX int `json:"max_data_rate_rx"` // kbit/s
+	MaxDataRateTX int `json:"max_data_rate_tx"` // kbit/s
+	CurDataRateRX int `json:"cur_data_rate_rx"` // kbit/s
+	CurDataRateTX int `json:"cur_data_rate_tx"` // kbit/s
+}
+
+// Connected tells if the link is up
+func (l *MeshLink) Connected() bool {
+	return l.State == "CONNECTED"
+}
+
+// MeshList downloads the mesh topology
+func (c *Client) MeshList(ctx context.Context) (*Mesh, error) {
+	path, err := c.XAVMDEGetMeshListPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Service.Fetch(ctx, path.XAVMDEMeshListPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch mesh list: %w", err)
+	}
+
+	var mesh Mesh
+	if err := json.Unmarshal(data, &mesh); err != nil {
+		return nil, fmt.Errorf("could not decode mesh list: %w", err)
+	}
+	return &mesh, nil
+}
@@ -0,0 +1,94 @@
+// Code generated by scpdgen from ethifconfigSCPD.xml. DO NOT EDIT.
+
+// Package lanethernetinterfaceconfig is a typed client for the LANEthernetInterfaceConfig service.
+package lanethernetinterfaceconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of LANEthernetInterfaceConfig
+const ServiceType = "urn:dslforum-org:service:LANEthernetInterfaceConfig:1"
+
+// Client calls the actions of a LANEthernetInterfaceConfig service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first LANEthernetInterfaceConfig service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetInfoResponse are the output arguments of GetInfo
+type GetInfoResponse struct {
+	Enable     bool   // NewEnable (boolean)
+	Status     string // NewStatus (string)
+	MACAddress string // NewMACAddress (string)
+	MaxBitRate string // NewMaxBitRate (string)
+	DuplexMode string // NewDuplexMode (string)
+}
+
+// GetInfo calls GetInfo
+func (c *Client) GetInfo(ctx context.Context) (GetInfoResponse, error) {
+	var resp GetInfoResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetInfo", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewEnable", &resp.Enable)
+	dec.Decode("NewStatus", &resp.Status)
+	dec.Decode("NewMACAddress", &resp.MACAddress)
+	dec.Decode("NewMaxBitRate", &resp.MaxBitRate)
+	dec.Decode("NewDuplexMode", &resp.DuplexMode)
+	return resp, dec.Err()
+}
+
+// GetStatisticsResponse are the output arguments of GetStatistics
+type GetStatisticsResponse struct {
+	BytesSent       uint64 // NewBytesSent (ui4)
+	BytesReceived   uint64 // NewBytesReceived (ui4)
+	PacketsSent     uint64 // NewPacketsSent (ui4)
+	PacketsReceived uint64 // NewPacketsReceived (ui4)
+}
+
+// GetStatistics calls GetStatistics
+func (c *Client) GetStatistics(ctx context.Context) (GetStatisticsResponse, error) {
+	var resp GetStatisticsResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetStatistics", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewBytesSent", &resp.BytesSent)
+	dec.Decode("NewBytesReceived", &resp.BytesReceived)
+	dec.Decode("NewPacketsSent", &resp.PacketsSent)
+	dec.Decode("NewPacketsReceived", &resp.PacketsReceived)
+	return resp, dec.Err()
+}
@@ -0,0 +1,49 @@
+package voip
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// NumberTypeVoIP is the type of numbers registered at a SIP registrar.
+// Other types are eISDN, ePOTS and eMobile.
+const NumberTypeVoIP = "eVoIP"
+
+// Number is an entry of the list returned by X_AVM-DE_GetNumbers
+type Number struct {
+	Number string `xml:"Number"`
+	Type   string `xml:"Type"`
+	Index  uint16 `xml:"Index"` // VoIP account index for eVoIP numbers
+	Name   string `xml:"Name"`
+}
+
+// Numbers returns all telephone numbers configured on the FRITZ!Box
+func (c *Client) Numbers(ctx context.Context) ([]Number, error) {
+	resp, err := c.XAVMDEGetNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []Number `xml:"Item"`
+	}
+	if err := xml.Unmarshal([]byte(resp.NumberList), &list); err != nil {
+		return nil, fmt.Errorf("could not decode number list: %w", err)
+	}
+	return list.Items, nil
+}
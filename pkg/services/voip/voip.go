@@ -0,0 +1,182 @@
+// Code generated by scpdgen from x_voipSCPD.xml. DO NOT EDIT.
+
+// Package voip is a typed client for the XVoIP service.
+package voip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of XVoIP
+const ServiceType = "urn:dslforum-org:service:X_VoIP:1"
+
+// Client calls the actions of a XVoIP service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first XVoIP service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetInfoExResponse are the output arguments of GetInfoEx
+type GetInfoExResponse struct {
+	VoIPNumberMinChars     uint16 // NewVoIPNumberMinChars (ui2)
+	VoIPNumberMaxChars     uint16 // NewVoIPNumberMaxChars (ui2)
+	VoIPNumberAllowedChars string // NewVoIPNumberAllowedChars (string)
+	VoIPEnableAreaCode     bool   // NewVoIPEnableAreaCode (boolean)
+	VoIPEnableCountryCode  bool   // NewVoIPEnableCountryCode (boolean)
+}
+
+// GetInfoEx calls GetInfoEx
+func (c *Client) GetInfoEx(ctx context.Context) (GetInfoExResponse, error) {
+	var resp GetInfoExResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetInfoEx", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewVoIPNumberMinChars", &resp.VoIPNumberMinChars)
+	dec.Decode("NewVoIPNumberMaxChars", &resp.VoIPNumberMaxChars)
+	dec.Decode("NewVoIPNumberAllowedChars", &resp.VoIPNumberAllowedChars)
+	dec.Decode("NewVoIPEnableAreaCode", &resp.VoIPEnableAreaCode)
+	dec.Decode("NewVoIPEnableCountryCode", &resp.VoIPEnableCountryCode)
+	return resp, dec.Err()
+}
+
+// GetExistingVoIPNumbersResponse are the output arguments of GetExistingVoIPNumbers
+type GetExistingVoIPNumbersResponse struct {
+	ExistingVoIPNumbers uint16 // NewExistingVoIPNumbers (ui2)
+}
+
+// GetExistingVoIPNumbers calls GetExistingVoIPNumbers
+func (c *Client) GetExistingVoIPNumbers(ctx context.Context) (GetExistingVoIPNumbersResponse, error) {
+	var resp GetExistingVoIPNumbersResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetExistingVoIPNumbers", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewExistingVoIPNumbers", &resp.ExistingVoIPNumbers)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetNumberOfNumbersResponse are the output arguments of X_AVM-DE_GetNumberOfNumbers
+type XAVMDEGetNumberOfNumbersResponse struct {
+	NumberOfNumbers uint64 // NewNumberOfNumbers (ui4)
+}
+
+// XAVMDEGetNumberOfNumbers calls X_AVM-DE_GetNumberOfNumbers
+func (c *Client) XAVMDEGetNumberOfNumbers(ctx context.Context) (XAVMDEGetNumberOfNumbersResponse, error) {
+	var resp XAVMDEGetNumberOfNumbersResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetNumberOfNumbers", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewNumberOfNumbers", &resp.NumberOfNumbers)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetNumbersResponse are the output arguments of X_AVM-DE_GetNumbers
+type XAVMDEGetNumbersResponse struct {
+	NumberList string // NewNumberList (string)
+}
+
+// XAVMDEGetNumbers calls X_AVM-DE_GetNumbers
+func (c *Client) XAVMDEGetNumbers(ctx context.Context) (XAVMDEGetNumbersResponse, error) {
+	var resp XAVMDEGetNumbersResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetNumbers", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewNumberList", &resp.NumberList)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetVoIPAccountResponse are the output arguments of X_AVM-DE_GetVoIPAccount
+type XAVMDEGetVoIPAccountResponse struct {
+	VoIPRegistrar     string // NewVoIPRegistrar (string)
+	VoIPNumber        string // NewVoIPNumber (string)
+	VoIPUsername      string // NewVoIPUsername (string)
+	VoIPPassword      string // NewVoIPPassword (string)
+	VoIPOutboundProxy string // NewVoIPOutboundProxy (string)
+	VoIPSTUNServer    string // NewVoIPSTUNServer (string)
+}
+
+// XAVMDEGetVoIPAccount calls X_AVM-DE_GetVoIPAccount
+func (c *Client) XAVMDEGetVoIPAccount(ctx context.Context, voIPAccountIndex uint16) (XAVMDEGetVoIPAccountResponse, error) {
+	var resp XAVMDEGetVoIPAccountResponse
+	args := map[string]interface{}{
+		"NewVoIPAccountIndex": voIPAccountIndex,
+	}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetVoIPAccount", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewVoIPRegistrar", &resp.VoIPRegistrar)
+	dec.Decode("NewVoIPNumber", &resp.VoIPNumber)
+	dec.Decode("NewVoIPUsername", &resp.VoIPUsername)
+	dec.Decode("NewVoIPPassword", &resp.VoIPPassword)
+	dec.Decode("NewVoIPOutboundProxy", &resp.VoIPOutboundProxy)
+	dec.Decode("NewVoIPSTUNServer", &resp.VoIPSTUNServer)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetVoIPStatusResponse are the output arguments of X_AVM-DE_GetVoIPStatus
+type XAVMDEGetVoIPStatusResponse struct {
+	XAVMDEVoIPStatus string // NewX_AVM-DE_VoIPStatus (string)
+}
+
+// XAVMDEGetVoIPStatus calls X_AVM-DE_GetVoIPStatus
+func (c *Client) XAVMDEGetVoIPStatus(ctx context.Context, voIPAccountIndex uint16) (XAVMDEGetVoIPStatusResponse, error) {
+	var resp XAVMDEGetVoIPStatusResponse
+	args := map[string]interface{}{
+		"NewVoIPAccountIndex": voIPAccountIndex,
+	}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetVoIPStatus", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewX_AVM-DE_VoIPStatus", &resp.XAVMDEVoIPStatus)
+	return resp, dec.Err()
+}
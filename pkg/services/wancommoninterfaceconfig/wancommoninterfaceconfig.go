@@ -0,0 +1,198 @@
+// Code generated by scpdgen from igdicfgSCPD.xml. DO NOT EDIT.
+
+// Package wancommoninterfaceconfig is a typed client for the WANCommonInterfaceConfig service.
+package wancommoninterfaceconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of WANCommonInterfaceConfig
+const ServiceType = "urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1"
+
+// Client calls the actions of a WANCommonInterfaceConfig service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first WANCommonInterfaceConfig service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetCommonLinkPropertiesResponse are the output arguments of GetCommonLinkProperties
+type GetCommonLinkPropertiesResponse struct {
+	WANAccessType              string // NewWANAccessType (string)
+	Layer1UpstreamMaxBitRate   uint64 // NewLayer1UpstreamMaxBitRate (ui4)
+	Layer1DownstreamMaxBitRate uint64 // NewLayer1DownstreamMaxBitRate (ui4)
+	PhysicalLinkStatus         string // NewPhysicalLinkStatus (string)
+}
+
+// GetCommonLinkProperties calls GetCommonLinkProperties
+func (c *Client) GetCommonLinkProperties(ctx context.Context) (GetCommonLinkPropertiesResponse, error) {
+	var resp GetCommonLinkPropertiesResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetCommonLinkProperties", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewWANAccessType", &resp.WANAccessType)
+	dec.Decode("NewLayer1UpstreamMaxBitRate", &resp.Layer1UpstreamMaxBitRate)
+	dec.Decode("NewLayer1DownstreamMaxBitRate", &resp.Layer1DownstreamMaxBitRate)
+	dec.Decode("NewPhysicalLinkStatus", &resp.PhysicalLinkStatus)
+	return resp, dec.Err()
+}
+
+// GetTotalBytesSentResponse are the output arguments of GetTotalBytesSent
+type GetTotalBytesSentResponse struct {
+	TotalBytesSent uint64 // NewTotalBytesSent (ui4)
+}
+
+// GetTotalBytesSent calls GetTotalBytesSent
+func (c *Client) GetTotalBytesSent(ctx context.Context) (GetTotalBytesSentResponse, error) {
+	var resp GetTotalBytesSentResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetTotalBytesSent", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewTotalBytesSent", &resp.TotalBytesSent)
+	return resp, dec.Err()
+}
+
+// GetTotalBytesReceivedResponse are the output arguments of GetTotalBytesReceived
+type GetTotalBytesReceivedResponse struct {
+	TotalBytesReceived uint64 // NewTotalBytesReceived (ui4)
+}
+
+// GetTotalBytesReceived calls GetTotalBytesReceived
+func (c *Client) GetTotalBytesReceived(ctx context.Context) (GetTotalBytesReceivedResponse, error) {
+	var resp GetTotalBytesReceivedResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetTotalBytesReceived", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewTotalBytesReceived", &resp.TotalBytesReceived)
+	return resp, dec.Err()
+}
+
+// GetTotalPacketsSentResponse are the output arguments of GetTotalPacketsSent
+type GetTotalPacketsSentResponse struct {
+	TotalPacketsSent uint64 // NewTotalPacketsSent (ui4)
+}
+
+// GetTotalPacketsSent calls GetTotalPacketsSent
+func (c *Client) GetTotalPacketsSent(ctx context.Context) (GetTotalPacketsSentResponse, error) {
+	var resp GetTotalPacketsSentResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetTotalPacketsSent", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewTotalPacketsSent", &resp.TotalPacketsSent)
+	return resp, dec.Err()
+}
+
+// GetTotalPacketsReceivedResponse are the output arguments of GetTotalPacketsReceived
+type GetTotalPacketsReceivedResponse struct {
+	TotalPacketsReceived uint64 // NewTotalPacketsReceived (ui4)
+}
+
+// GetTotalPacketsReceived calls GetTotalPacketsReceived
+func (c *Client) GetTotalPacketsReceived(ctx context.Context) (GetTotalPacketsReceivedResponse, error) {
+	var resp GetTotalPacketsReceivedResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetTotalPacketsReceived", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewTotalPacketsReceived", &resp.TotalPacketsReceived)
+	return resp, dec.Err()
+}
+
+// GetAddonInfosResponse are the output arguments of GetAddonInfos
+type GetAddonInfosResponse struct {
+	ByteSendRate               uint64 // NewByteSendRate (ui4)
+	ByteReceiveRate            uint64 // NewByteReceiveRate (ui4)
+	PacketSendRate             uint64 // NewPacketSendRate (ui4)
+	PacketReceiveRate          uint64 // NewPacketReceiveRate (ui4)
+	TotalBytesSent             uint64 // NewTotalBytesSent (ui4)
+	TotalBytesReceived         uint64 // NewTotalBytesReceived (ui4)
+	AutoDisconnectTime         uint64 // NewAutoDisconnectTime (ui4)
+	IdleDisconnectTime         uint64 // NewIdleDisconnectTime (ui4)
+	DNSServer1                 string // NewDNSServer1 (string)
+	DNSServer2                 string // NewDNSServer2 (string)
+	VoipDNSServer1             string // NewVoipDNSServer1 (string)
+	VoipDNSServer2             string // NewVoipDNSServer2 (string)
+	UpnpControlEnabled         bool   // NewUpnpControlEnabled (boolean)
+	RoutedBridgedModeBoth      uint8  // NewRoutedBridgedModeBoth (ui1)
+	XAVMDETotalBytesSent64     uint64 // NewX_AVM_DE_TotalBytesSent64 (string)
+	XAVMDETotalBytesReceived64 uint64 // NewX_AVM_DE_TotalBytesReceived64 (string)
+	XAVMDEWANAccessType        string // NewX_AVM_DE_WANAccessType (string)
+}
+
+// GetAddonInfos calls GetAddonInfos
+func (c *Client) GetAddonInfos(ctx context.Context) (GetAddonInfosResponse, error) {
+	var resp GetAddonInfosResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetAddonInfos", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewByteSendRate", &resp.ByteSendRate)
+	dec.Decode("NewByteReceiveRate", &resp.ByteReceiveRate)
+	dec.Decode("NewPacketSendRate", &resp.PacketSendRate)
+	dec.Decode("NewPacketReceiveRate", &resp.PacketReceiveRate)
+	dec.Decode("NewTotalBytesSent", &resp.TotalBytesSent)
+	dec.Decode("NewTotalBytesReceived", &resp.TotalBytesReceived)
+	dec.Decode("NewAutoDisconnectTime", &resp.AutoDisconnectTime)
+	dec.Decode("NewIdleDisconnectTime", &resp.IdleDisconnectTime)
+	dec.Decode("NewDNSServer1", &resp.DNSServer1)
+	dec.Decode("NewDNSServer2", &resp.DNSServer2)
+	dec.Decode("NewVoipDNSServer1", &resp.VoipDNSServer1)
+	dec.Decode("NewVoipDNSServer2", &resp.VoipDNSServer2)
+	dec.Decode("NewUpnpControlEnabled", &resp.UpnpControlEnabled)
+	dec.Decode("NewRoutedBridgedModeBoth", &resp.RoutedBridgedModeBoth)
+	dec.Decode("NewX_AVM_DE_TotalBytesSent64", &resp.XAVMDETotalBytesSent64)
+	dec.Decode("NewX_AVM_DE_TotalBytesReceived64", &resp.XAVMDETotalBytesReceived64)
+	dec.Decode("NewX_AVM_DE_WANAccessType", &resp.XAVMDEWANAccessType)
+	return resp, dec.Err()
+}
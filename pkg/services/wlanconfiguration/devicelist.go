@@ -0,0 +1,88 @@
+package wlanconfiguration
+
+// Copyright 2016 Nils Decker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// serviceTypePrefix matches all versions of the service type. Some firmware
+// numbers the instances in the version (WLANConfiguration:1, :2, :3).
+const serviceTypePrefix = "urn:dslforum-org:service:WLANConfiguration:"
+
+// All returns a client for every WLANConfiguration instance of the tree from the
+// given source, one per radio and guest network, ordered by instance number.
+func All(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) []*Client {
+	seen := make(map[string]bool)
+	var types []string
+	for _, s := range root.Instances {
+		if strings.HasPrefix(s.ServiceType, serviceTypePrefix) && !seen[s.ServiceType] {
+			seen[s.ServiceType] = true
+			types = append(types, s.ServiceType)
+		}
+	}
+	sort.Strings(types)
+
+	var clients []*Client
+	for _, t := range types {
+		for _, s := range root.ServicesByType(source, t) {
+			clients = append(clients, &Client{Service: s})
+		}
+	}
+	return clients
+}
+
+// Device is an entry of the list of associated devices downloaded from
+// X_AVM-DE_GetWLANDeviceListPath
+type Device struct {
+	Index          int    `xml:"AssociatedDeviceIndex"`
+	MACAddress     string `xml:"AssociatedDeviceMACAddress"`
+	IPAddress      string `xml:"AssociatedDeviceIPAddress"`
+	AuthState      bool   `xml:"AssociatedDeviceAuthState"`
+	Speed          int    `xml:"X_AVM-DE_Speed"`          // Mbit/s
+	SignalStrength int    `xml:"X_AVM-DE_SignalStrength"` // Percent
+	Channel        int    `xml:"AssociatedDeviceChannel"`
+	Guest          bool   `xml:"AssociatedDeviceGuest"`
+	SpeedRX        int    `xml:"X_AVM-DE_SpeedRX"` // Mbit/s
+	SpeedTX        int    `xml:"X_AVM-DE_SpeedTX"` // Mbit/s
+}
+
+// DeviceList downloads the list of associated devices with a single request,
+// instead of calling GetGenericAssociatedDeviceInfo for every device
+func (c *Client) DeviceList(ctx context.Context) ([]Device, error) {
+	path, err := c.XAVMDEGetWLANDeviceListPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Service.Fetch(ctx, path.XAVMDEWLANDeviceListPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch WLAN device list: %w", err)
+	}
+
+	var list struct {
+		Items []Device `xml:"Item"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("could not decode WLAN device list: %w", err)
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,150 @@
+// Code generated by scpdgen from wlanconfigSCPD.xml. DO NOT EDIT.
+
+// Package wlanconfiguration is a typed client for the WLANConfiguration service.
+package wlanconfiguration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mxschmitt/fritzbox_exporter/pkg/fritzboxmetrics"
+)
+
+// ServiceType is the service type of WLANConfiguration
+const ServiceType = "urn:dslforum-org:service:WLANConfiguration:1"
+
+// Client calls the actions of a WLANConfiguration service instance
+type Client struct {
+	Service *fritzboxmetrics.Service
+}
+
+// New returns a client for the first WLANConfiguration service of the tree from the given source.
+func New(root *fritzboxmetrics.Root, source fritzboxmetrics.ServiceSource) (*Client, error) {
+	service, ok := root.Service(source, ServiceType)
+	if !ok {
+		return nil, fmt.Errorf("could not find service %s", ServiceType)
+	}
+	return &Client{Service: service}, nil
+}
+
+func (c *Client) call(ctx context.Context, name string, args map[string]interface{}) (*fritzboxmetrics.Action, fritzboxmetrics.Result, error) {
+	action, ok := c.Service.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find action %s", name)
+	}
+	res, err := action.CallWithArgsContext(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return action, res, nil
+}
+
+// GetInfoResponse are the output arguments of GetInfo
+type GetInfoResponse struct {
+	Enable                   bool   // NewEnable (boolean)
+	Status                   string // NewStatus (string)
+	MaxBitRate               string // NewMaxBitRate (string)
+	Channel                  uint8  // NewChannel (ui1)
+	SSID                     string // NewSSID (string)
+	BeaconType               string // NewBeaconType (string)
+	MACAddressControlEnabled bool   // NewMACAddressControlEnabled (boolean)
+	Standard                 string // NewStandard (string)
+	BSSID                    string // NewBSSID (string)
+	BasicEncryptionModes     string // NewBasicEncryptionModes (string)
+	BasicAuthenticationMode  string // NewBasicAuthenticationMode (string)
+}
+
+// GetInfo calls GetInfo
+func (c *Client) GetInfo(ctx context.Context) (GetInfoResponse, error) {
+	var resp GetInfoResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetInfo", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewEnable", &resp.Enable)
+	dec.Decode("NewStatus", &resp.Status)
+	dec.Decode("NewMaxBitRate", &resp.MaxBitRate)
+	dec.Decode("NewChannel", &resp.Channel)
+	dec.Decode("NewSSID", &resp.SSID)
+	dec.Decode("NewBeaconType", &resp.BeaconType)
+	dec.Decode("NewMACAddressControlEnabled", &resp.MACAddressControlEnabled)
+	dec.Decode("NewStandard", &resp.Standard)
+	dec.Decode("NewBSSID", &resp.BSSID)
+	dec.Decode("NewBasicEncryptionModes", &resp.BasicEncryptionModes)
+	dec.Decode("NewBasicAuthenticationMode", &resp.BasicAuthenticationMode)
+	return resp, dec.Err()
+}
+
+// GetTotalAssociationsResponse are the output arguments of GetTotalAssociations
+type GetTotalAssociationsResponse struct {
+	TotalAssociations uint16 // NewTotalAssociations (ui2)
+}
+
+// GetTotalAssociations calls GetTotalAssociations
+func (c *Client) GetTotalAssociations(ctx context.Context) (GetTotalAssociationsResponse, error) {
+	var resp GetTotalAssociationsResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "GetTotalAssociations", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewTotalAssociations", &resp.TotalAssociations)
+	return resp, dec.Err()
+}
+
+// GetGenericAssociatedDeviceInfoResponse are the output arguments of GetGenericAssociatedDeviceInfo
+type GetGenericAssociatedDeviceInfoResponse struct {
+	AssociatedDeviceMACAddress string // NewAssociatedDeviceMACAddress (string)
+	AssociatedDeviceIPAddress  string // NewAssociatedDeviceIPAddress (string)
+	AssociatedDeviceAuthState  bool   // NewAssociatedDeviceAuthState (boolean)
+	XAVMDESpeed                uint64 // NewX_AVM-DE_Speed (ui4)
+	XAVMDESignalStrength       uint8  // NewX_AVM-DE_SignalStrength (ui1)
+}
+
+// GetGenericAssociatedDeviceInfo calls GetGenericAssociatedDeviceInfo
+func (c *Client) GetGenericAssociatedDeviceInfo(ctx context.Context, associatedDeviceIndex uint16) (GetGenericAssociatedDeviceInfoResponse, error) {
+	var resp GetGenericAssociatedDeviceInfoResponse
+	args := map[string]interface{}{
+		"NewAssociatedDeviceIndex": associatedDeviceIndex,
+	}
+
+	action, res, err := c.call(ctx, "GetGenericAssociatedDeviceInfo", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewAssociatedDeviceMACAddress", &resp.AssociatedDeviceMACAddress)
+	dec.Decode("NewAssociatedDeviceIPAddress", &resp.AssociatedDeviceIPAddress)
+	dec.Decode("NewAssociatedDeviceAuthState", &resp.AssociatedDeviceAuthState)
+	dec.Decode("NewX_AVM-DE_Speed", &resp.XAVMDESpeed)
+	dec.Decode("NewX_AVM-DE_SignalStrength", &resp.XAVMDESignalStrength)
+	return resp, dec.Err()
+}
+
+// XAVMDEGetWLANDeviceListPathResponse are the output arguments of X_AVM-DE_GetWLANDeviceListPath
+type XAVMDEGetWLANDeviceListPathResponse struct {
+	XAVMDEWLANDeviceListPath string // NewX_AVM-DE_WLANDeviceListPath (string)
+}
+
+// XAVMDEGetWLANDeviceListPath calls X_AVM-DE_GetWLANDeviceListPath
+func (c *Client) XAVMDEGetWLANDeviceListPath(ctx context.Context) (XAVMDEGetWLANDeviceListPathResponse, error) {
+	var resp XAVMDEGetWLANDeviceListPathResponse
+	var args map[string]interface{}
+
+	action, res, err := c.call(ctx, "X_AVM-DE_GetWLANDeviceListPath", args)
+	if err != nil {
+		return resp, err
+	}
+
+	dec := action.NewOutputDecoder(res)
+	dec.Decode("NewX_AVM-DE_WLANDeviceListPath", &resp.XAVMDEWLANDeviceListPath)
+	return resp, dec.Err()
+}